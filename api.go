@@ -0,0 +1,1172 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	apiTemplatePath    = "template/service/_api"
+	apiGeneratedHeader = "// Code generated by beginning add api. DO NOT EDIT."
+	apiSourcePrefix    = "// Source: "
+	apiSchemaDir       = "internal/entrypoint/httpd/schema"
+	apiControllerDir   = "internal/entrypoint/httpd/controller"
+	apiRouterDir       = "internal/entrypoint/httpd/router"
+	apiRouterIndexFile = "internal/entrypoint/httpd/router/api.gen.go"
+	apiJSONTag         = "json"
+)
+
+var (
+	specFile   string
+	projectDir string
+	regenerate bool
+)
+
+var (
+	reGoModule   = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	reVersion    = regexp.MustCompile(`^v[0-9]+$`)
+	reTypeIdent  = regexp.MustCompile(`(^|[^.\w])([A-Z]\w*)`)
+	rePathParams = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+// commonInitialisms are kept upper case in Go names, as golint does.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "JWT": true, "QPS": true, "RAM": true, "RPC": true,
+	"SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true,
+	"URL": true, "UTF8": true, "VM": true, "XML": true, "XSRF": true, "XSS": true,
+}
+
+// apiModel is the data of the _api templates.
+type apiModel struct {
+	ModuleName string
+	Source     string
+	Types      []*apiType
+	Tags       []*apiTag
+	UsesTime   bool
+	Tag        *apiTag
+	Operations []*apiOperation
+}
+
+type apiType struct {
+	Name   string
+	Doc    string
+	Kind   string // struct, enum or named
+	Type   string // underlying type of enum and named types
+	Embeds []string
+	Fields []*apiField
+	Values []apiEnumValue
+}
+
+type apiField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+type apiEnumValue struct {
+	Name  string
+	Value string
+}
+
+type apiTag struct {
+	Name               string
+	FileName           string
+	Interface          string
+	Controller         string
+	ControllerReceiver string
+	Router             string
+	RouterReceiver     string
+	BasePath           string
+	Version            string
+	Operations         []*apiOperation
+	UsesErrors         bool
+	UsesMiddleware     bool
+}
+
+type apiOperation struct {
+	Name         string
+	Handler      string
+	Doc          string
+	Method       string
+	Path         string
+	Request      string
+	HasPath      bool
+	HasQuery     bool
+	HasHeader    bool
+	Body         string
+	BodyRequired bool
+	Middlewares  []string
+	Responses    []apiResponse
+}
+
+type apiResponse struct {
+	Status string
+	Type   string
+}
+
+// typeInfo describes a Go type generated for a schema.
+type typeInfo struct {
+	Expr string
+	// Kind is one of string, number, bool, time, struct, slice, map or any.
+	Kind string
+	// Struct is set for slices whose elements are structs, to validate them.
+	Struct bool
+}
+
+// apiGenerator turns a spec into the apiModel.
+type apiGenerator struct {
+	spec     *openAPISpec
+	auth     bool
+	model    *apiModel
+	names    map[string]bool
+	warnings []string
+}
+
+func runAddAPI(cmd *cobra.Command, args []string) {
+	dir, err := filepath.Abs(projectDir)
+	check(err)
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		fmt.Printf("❌ No go.mod in %s, run this command in a generated service\n", dir)
+		os.Exit(1)
+	}
+	match := reGoModule.FindSubmatch(goMod)
+	if match == nil {
+		fmt.Printf("❌ No module directive in %s\n", filepath.Join(dir, "go.mod"))
+		os.Exit(1)
+	}
+	if !fileExists(filepath.Join(dir, apiRouterIndexFile)) {
+		fmt.Printf("❌ %s is missing, %s is not a service generated by beginning\n", apiRouterIndexFile, dir)
+		os.Exit(1)
+	}
+
+	source := specFile
+	if source == "" {
+		// Regenerate from the spec recorded by the previous run.
+		source = apiRecordedSource(filepath.Join(dir, apiRouterIndexFile))
+		if source == "" {
+			fmt.Println("❌ --spec is required, no spec was recorded by a previous 'beginning add api'")
+			os.Exit(1)
+		}
+		source = filepath.Join(dir, source)
+	}
+	source, err = filepath.Abs(source)
+	check(err)
+	spec, err := loadOpenAPISpec(source)
+	if err != nil {
+		fmt.Printf("❌ Error reading spec: %v\n", err)
+		os.Exit(1)
+	}
+	relSource, err := filepath.Rel(dir, source)
+	if err != nil || strings.HasPrefix(relSource, "..") {
+		// Specs outside the project cannot be found again by --regenerate.
+		relSource = source
+	}
+
+	generator := &apiGenerator{
+		spec:  spec,
+		auth:  fileExists(filepath.Join(dir, "internal/auth")),
+		names: map[string]bool{},
+		model: &apiModel{ModuleName: string(match[1]), Source: filepath.ToSlash(relSource)},
+	}
+	if err := generator.generate(); err != nil {
+		fmt.Printf("❌ Error generating API: %v\n", err)
+		os.Exit(1)
+	}
+	if err := generator.checkConflicts(dir); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range generator.warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	check(writeAPI(dir, generator.model))
+
+	if fileExists(filepath.Join(dir, "bin/wire.sh")) {
+		fmt.Println("⚙️  Running: ./bin/wire.sh")
+		wire := exec.Command("bash", "-c", "./bin/wire.sh")
+		wire.Dir = dir
+		wire.Stdout = os.Stdout
+		wire.Stderr = os.Stderr
+		if err := wire.Run(); err != nil {
+			fmt.Printf("⚠️  wire failed (%v), fix the errors above and run ./bin/wire.sh\n", err)
+		}
+	}
+	fmt.Printf("✅ API generated from %s: %d operations in %d controllers\n", relSource, countOperations(generator.model), len(generator.model.Tags))
+}
+
+// apiRecordedSource returns the spec path written by the previous run.
+func apiRecordedSource(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, apiSourcePrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, apiSourcePrefix))
+		}
+	}
+	return ""
+}
+
+func countOperations(model *apiModel) int {
+	count := 0
+	for _, tag := range model.Tags {
+		count += len(tag.Operations)
+	}
+	return count
+}
+
+func (g *apiGenerator) warnf(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// declare adds a type to the schema package, rejecting duplicate names.
+func (g *apiGenerator) declare(t *apiType) error {
+	if g.names[t.Name] {
+		return fmt.Errorf("type %s is generated twice, rename the schema or operation it comes from", t.Name)
+	}
+	g.names[t.Name] = true
+	g.model.Types = append(g.model.Types, t)
+	return nil
+}
+
+func (g *apiGenerator) generate() error {
+	for _, name := range g.spec.Components.Schemas.Keys {
+		if err := g.declareSchema(goName(name), g.spec.Components.Schemas.Values[name]); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	basePath, version := apiServerPath(g.spec)
+	tags := map[string]*apiTag{}
+	operations := map[string]bool{}
+	for _, path := range g.spec.Paths.Keys {
+		pathItem := g.spec.Paths.Values[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range pathItem.operations() {
+			operation, err := g.operation(path, method.Method, pathItem, method.Operation)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", method.Method, path, err)
+			}
+			if operations[operation.Name] {
+				return fmt.Errorf("%s %s: operation %s is defined twice", method.Method, path, operation.Name)
+			}
+			operations[operation.Name] = true
+
+			tagName := "default"
+			if len(method.Operation.Tags) > 0 {
+				tagName = method.Operation.Tags[0]
+			}
+			tag, ok := tags[goName(tagName)]
+			if !ok {
+				tag = newAPITag(tagName, basePath, version)
+				tags[tag.Name] = tag
+				g.model.Tags = append(g.model.Tags, tag)
+			}
+			tag.Operations = append(tag.Operations, operation)
+			tag.UsesErrors = tag.UsesErrors || operation.BodyRequired
+			tag.UsesMiddleware = tag.UsesMiddleware || len(operation.Middlewares) > 0
+		}
+	}
+	sort.Slice(g.model.Tags, func(i, j int) bool {
+		return g.model.Tags[i].Name < g.model.Tags[j].Name
+	})
+	return nil
+}
+
+func newAPITag(name string, basePath string, version string) *apiTag {
+	words := splitWords(name)
+	tag := &apiTag{
+		Name:     goName(name),
+		BasePath: basePath,
+		Version:  version,
+	}
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	tag.FileName = strings.Join(words, "_")
+	tag.Interface = tag.Name + "API"
+	tag.Controller = tag.Name + "Controller"
+	tag.ControllerReceiver = lowerCamel(name + " controller")
+	tag.Router = tag.Name + "Router"
+	tag.RouterReceiver = lowerCamel(name + " router")
+	return tag
+}
+
+// apiServerPath maps the path of the first server to the routers: a trailing
+// version segment (/api/v1) makes them versioned routers mounted under
+// server.apiPrefix, any other path becomes their base path.
+func apiServerPath(spec *openAPISpec) (string, string) {
+	if len(spec.Servers) == 0 {
+		return "", ""
+	}
+	serverPath := spec.Servers[0].URL
+	if u, err := url.Parse(serverPath); err == nil {
+		serverPath = u.Path
+	}
+	serverPath = strings.TrimRight(serverPath, "/")
+	if segment := serverPath[strings.LastIndex(serverPath, "/")+1:]; reVersion.MatchString(segment) {
+		return "", segment
+	}
+	return serverPath, ""
+}
+
+func (g *apiGenerator) operation(path string, method string, pathItem *openAPIPathItem, spec *openAPIOperation) (*apiOperation, error) {
+	name := spec.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + " " + rePathParams.ReplaceAllString(path, "by $1")
+	}
+	operation := &apiOperation{
+		Name:    goName(name),
+		Handler: lowerCamel(name),
+		Method:  method,
+		Path:    rePathParams.ReplaceAllString(path, ":$1"),
+	}
+	operation.Doc = fmt.Sprintf("%s handles %s %s.", operation.Name, method, path)
+	if summary := firstLine(spec.Summary); summary != "" {
+		operation.Doc += "\n// " + summary
+	} else if description := firstLine(spec.Description); description != "" {
+		operation.Doc += "\n// " + description
+	}
+	if spec.Deprecated {
+		operation.Doc += "\n//\n// Deprecated: the operation is deprecated in the spec."
+	}
+
+	// Operation parameters override the ones of the path with the same
+	// name and location.
+	parameters := map[string]*openAPIParameter{}
+	var order []string
+	for _, list := range [][]*openAPIParameter{pathItem.Parameters, spec.Parameters} {
+		for _, parameter := range list {
+			resolved, err := g.spec.parameter(parameter)
+			if err != nil {
+				return nil, err
+			}
+			key := resolved.In + ":" + resolved.Name
+			if _, ok := parameters[key]; !ok {
+				order = append(order, key)
+			}
+			parameters[key] = resolved
+		}
+	}
+	groups := map[string]*apiType{}
+	for _, key := range order {
+		parameter := parameters[key]
+		var suffix, tagKey string
+		switch parameter.In {
+		case "path":
+			suffix, tagKey = "Path", "uri"
+			parameter.Required = true
+		case "query":
+			suffix, tagKey = "Query", "form"
+		case "header":
+			suffix, tagKey = "Header", "header"
+		default:
+			g.warnf("%s: %s parameter %q is not bound", operation.Name, parameter.In, parameter.Name)
+			continue
+		}
+		group, ok := groups[suffix]
+		if !ok {
+			group = &apiType{
+				Name: operation.Name + suffix,
+				Doc:  fmt.Sprintf("%s are the %s parameters of %s.", operation.Name+suffix, parameter.In, operation.Name),
+				Kind: "struct",
+			}
+			groups[suffix] = group
+		}
+		field, err := g.field(parameter.Name, parameter.Schema, parameter.Required, operation.Name+goName(parameter.Name), tagKey)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", parameter.Name, err)
+		}
+		if field.Doc == "" {
+			field.Doc = firstLine(parameter.Description)
+		}
+		group.Fields = append(group.Fields, field)
+	}
+
+	request := &apiType{
+		Name: operation.Name + "Request",
+		Doc:  fmt.Sprintf("%s is bound from the request of %s.", operation.Name+"Request", operation.Name),
+		Kind: "struct",
+	}
+	operation.Request = request.Name
+	for _, suffix := range []string{"Path", "Query", "Header"} {
+		group, ok := groups[suffix]
+		if !ok {
+			continue
+		}
+		if err := g.declare(group); err != nil {
+			return nil, err
+		}
+		request.Fields = append(request.Fields, &apiField{Name: suffix, Type: group.Name})
+	}
+	operation.HasPath, operation.HasQuery, operation.HasHeader = groups["Path"] != nil, groups["Query"] != nil, groups["Header"] != nil
+
+	if spec.RequestBody != nil {
+		requestBody, err := g.spec.requestBody(spec.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		schema := jsonSchema(requestBody.Content)
+		if schema == nil {
+			return nil, fmt.Errorf("only application/json request bodies are supported")
+		}
+		info, err := g.goType(schema, operation.Name+"RequestBody")
+		if err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+		operation.Body, operation.BodyRequired = info.Expr, requestBody.Required
+		request.Fields = append(request.Fields, &apiField{Name: "Body", Type: info.Expr, Doc: firstLine(requestBody.Description)})
+	}
+	if err := g.declare(request); err != nil {
+		return nil, err
+	}
+
+	success := false
+	for _, status := range spec.Responses.Keys {
+		response, err := g.spec.response(spec.Responses.Values[status])
+		if err != nil {
+			return nil, err
+		}
+		result := apiResponse{Status: status}
+		if schema := jsonSchema(response.Content); schema != nil {
+			name := operation.Name + goName(status) + "Response"
+			if status == "default" {
+				name = operation.Name + "DefaultResponse"
+			} else if strings.HasPrefix(status, "2") && !success {
+				name, success = operation.Name+"Response", true
+			}
+			info, err := g.goType(schema, name)
+			if err != nil {
+				return nil, fmt.Errorf("response %s: %w", status, err)
+			}
+			result.Type = qualifySchemaType(info.Expr)
+		}
+		operation.Responses = append(operation.Responses, result)
+	}
+
+	operation.Middlewares = g.securityMiddlewares(operation.Name, spec)
+	return operation, nil
+}
+
+// securityMiddlewares guards an operation with the auth component: any
+// bearer requirement needs a valid token, and the scopes of a single
+// requirement are all required.
+func (g *apiGenerator) securityMiddlewares(name string, spec *openAPIOperation) []string {
+	requirements := g.spec.Security
+	if spec.Security != nil {
+		requirements = *spec.Security
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			// {} makes authentication optional.
+			return nil
+		}
+	}
+	if !g.auth {
+		g.warnf("%s: security requirements are ignored without the auth component", name)
+		return nil
+	}
+	middlewares := []string{"middleware.RequireAuth()"}
+	if len(requirements) == 1 {
+		var scopes []string
+		for schemeName, schemeScopes := range requirements[0] {
+			if scheme, ok := g.spec.Components.SecuritySchemes[schemeName]; ok && scheme.Type == "apiKey" {
+				g.warnf("%s: api key scheme %q is checked as a bearer token", name, schemeName)
+			}
+			for _, scope := range schemeScopes {
+				scopes = append(scopes, strconv.Quote(scope))
+			}
+		}
+		if len(scopes) > 0 {
+			sort.Strings(scopes)
+			middlewares = []string{fmt.Sprintf("middleware.RequireScopes(%s)", strings.Join(scopes, ", "))}
+		}
+	}
+	return middlewares
+}
+
+// declareSchema declares a component schema as a Go type of the same name.
+func (g *apiGenerator) declareSchema(name string, schema *openAPISchema) error {
+	if schema == nil {
+		return g.declare(&apiType{Name: name, Kind: "named", Type: "any", Doc: name + " is any JSON value."})
+	}
+	doc := typeDoc(name, schema.Description)
+	if isObjectSchema(schema) {
+		return g.declareStruct(name, schema)
+	}
+	if schema.Ref != "" {
+		info, err := g.goType(schema, name)
+		if err != nil {
+			return err
+		}
+		return g.declare(&apiType{Name: name, Kind: "named", Type: info.Expr, Doc: doc})
+	}
+	if schema.Type.Name == "string" && len(schema.Enum) > 0 && schema.Format == "" {
+		enum := &apiType{Name: name, Kind: "enum", Type: "string", Doc: doc}
+		for _, value := range schema.Enum {
+			text := fmt.Sprint(value)
+			enum.Values = append(enum.Values, apiEnumValue{Name: name + goName(text), Value: strconv.Quote(text)})
+		}
+		return g.declare(enum)
+	}
+	// Reserve the name first so inline types of arrays and maps are named
+	// after it, e.g. PetsItem.
+	t := &apiType{Name: name, Kind: "named", Doc: doc}
+	if err := g.declare(t); err != nil {
+		return err
+	}
+	info, err := g.goType(schema, name+"Item")
+	if err != nil {
+		return err
+	}
+	t.Type = info.Expr
+	return nil
+}
+
+func isObjectSchema(schema *openAPISchema) bool {
+	return schema.Ref == "" && (len(schema.Properties.Keys) > 0 || len(schema.AllOf) > 1 ||
+		(len(schema.AllOf) == 1 && len(schema.Properties.Keys) > 0) ||
+		(schema.Type.Name == "object" && schema.AdditionalProperties == nil && len(schema.AllOf) == 0))
+}
+
+// declareStruct declares an object schema. allOf references are embedded
+// and inline allOf members are merged in.
+func (g *apiGenerator) declareStruct(name string, schema *openAPISchema) error {
+	t := &apiType{Name: name, Kind: "struct", Doc: typeDoc(name, schema.Description)}
+	if err := g.declare(t); err != nil {
+		return err
+	}
+	parts := []*openAPISchema{schema}
+	for _, member := range schema.AllOf {
+		if member.Ref != "" {
+			refType, err := g.refType(member.Ref)
+			if err != nil {
+				return err
+			}
+			t.Embeds = append(t.Embeds, refType)
+			continue
+		}
+		parts = append(parts, member)
+	}
+	seen := map[string]bool{}
+	for _, part := range parts {
+		for _, property := range part.Properties.Keys {
+			if seen[property] {
+				continue
+			}
+			seen[property] = true
+			required := false
+			for _, candidate := range parts {
+				required = required || containsString(candidate.Required, property)
+			}
+			field, err := g.field(property, part.Properties.Values[property], required, name+goName(property), apiJSONTag)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", property, err)
+			}
+			t.Fields = append(t.Fields, field)
+		}
+	}
+	return nil
+}
+
+func (g *apiGenerator) refType(ref string) (string, error) {
+	name, err := refName(ref, "schemas")
+	if err != nil {
+		return "", err
+	}
+	if _, ok := g.spec.Components.Schemas.Values[name]; !ok {
+		return "", fmt.Errorf("schema %q is not defined", ref)
+	}
+	return goName(name), nil
+}
+
+// refKind follows references to tell which kind of Go type a schema becomes.
+func (g *apiGenerator) refKind(schema *openAPISchema, depth int) string {
+	if schema == nil || depth > 32 {
+		return "any"
+	}
+	if schema.Ref != "" {
+		name, err := refName(schema.Ref, "schemas")
+		if err != nil {
+			return "any"
+		}
+		return g.refKind(g.spec.Components.Schemas.Values[name], depth+1)
+	}
+	if isObjectSchema(schema) {
+		return "struct"
+	}
+	if len(schema.AllOf) == 1 {
+		return g.refKind(schema.AllOf[0], depth+1)
+	}
+	switch schema.Type.Name {
+	case "string":
+		if schema.Format == "date-time" {
+			return "time"
+		}
+		if schema.Format == "binary" || schema.Format == "byte" {
+			return "slice"
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "slice"
+	case "object":
+		return "map"
+	}
+	return "any"
+}
+
+// goType returns the Go type of schema, declaring inline objects as name.
+func (g *apiGenerator) goType(schema *openAPISchema, name string) (typeInfo, error) {
+	if schema == nil {
+		return typeInfo{Expr: "any", Kind: "any"}, nil
+	}
+	if schema.Ref != "" {
+		refType, err := g.refType(schema.Ref)
+		if err != nil {
+			return typeInfo{}, err
+		}
+		return typeInfo{Expr: refType, Kind: g.refKind(schema, 0)}, nil
+	}
+	if isObjectSchema(schema) {
+		if err := g.declareStruct(name, schema); err != nil {
+			return typeInfo{}, err
+		}
+		return typeInfo{Expr: name, Kind: "struct"}, nil
+	}
+	if len(schema.AllOf) == 1 {
+		// allOf with a single member is used to annotate a reference.
+		return g.goType(schema.AllOf[0], name)
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return typeInfo{Expr: "any", Kind: "any"}, nil
+	}
+	switch schema.Type.Name {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			g.model.UsesTime = true
+			return typeInfo{Expr: "time.Time", Kind: "time"}, nil
+		case "binary", "byte":
+			return typeInfo{Expr: "[]byte", Kind: "slice"}, nil
+		}
+		return typeInfo{Expr: "string", Kind: "string"}, nil
+	case "integer":
+		if schema.Format == "int32" {
+			return typeInfo{Expr: "int32", Kind: "number"}, nil
+		}
+		return typeInfo{Expr: "int64", Kind: "number"}, nil
+	case "number":
+		if schema.Format == "float" {
+			return typeInfo{Expr: "float32", Kind: "number"}, nil
+		}
+		return typeInfo{Expr: "float64", Kind: "number"}, nil
+	case "boolean":
+		return typeInfo{Expr: "bool", Kind: "bool"}, nil
+	case "array":
+		item, err := g.goType(schema.Items, name+"Item")
+		if err != nil {
+			return typeInfo{}, err
+		}
+		return typeInfo{Expr: "[]" + item.Expr, Kind: "slice", Struct: item.Kind == "struct"}, nil
+	case "object":
+		value := typeInfo{Expr: "any", Kind: "any"}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			var err error
+			if value, err = g.goType(schema.AdditionalProperties.Schema, name+"Value"); err != nil {
+				return typeInfo{}, err
+			}
+		}
+		return typeInfo{Expr: "map[string]" + value.Expr, Kind: "map"}, nil
+	}
+	return typeInfo{Expr: "any", Kind: "any"}, nil
+}
+
+// field returns the struct field of a property or parameter. Optional and
+// nullable scalars and objects are pointers so that absence can be told
+// apart from the zero value.
+func (g *apiGenerator) field(name string, schema *openAPISchema, required bool, typeName string, tagKey string) (*apiField, error) {
+	info, err := g.goType(schema, typeName)
+	if err != nil {
+		return nil, err
+	}
+	if tagKey != apiJSONTag && (info.Kind == "struct" || info.Kind == "map" || (info.Kind == "slice" && info.Struct)) {
+		return nil, fmt.Errorf("objects are only supported in JSON bodies")
+	}
+	nullable := schema != nil && (schema.Nullable || schema.Type.Nullable)
+	pointer := (!required || nullable) && info.Kind != "slice" && info.Kind != "map" && info.Kind != "any"
+	field := &apiField{Name: goName(name), Type: info.Expr}
+	if pointer {
+		field.Type = "*" + info.Expr
+	}
+	if schema != nil {
+		field.Doc = firstLine(schema.Description)
+	}
+
+	tag := fmt.Sprintf(`%s:"%s"`, tagKey, name)
+	if tagKey == apiJSONTag && !required {
+		tag = fmt.Sprintf(`json:"%s,omitempty"`, name)
+	}
+	if rules := validationRules(schema, info, required && !nullable); len(rules) > 0 {
+		if rules[0] != "required" {
+			rules = append([]string{"omitempty"}, rules...)
+		}
+		tag += fmt.Sprintf(` binding:"%s"`, strings.Join(rules, ","))
+	}
+	field.Tag = tag
+	return field, nil
+}
+
+// validationRules maps the schema constraints to go-playground/validator
+// rules, which gin runs when binding. Required numbers and booleans are not
+// checked as their zero value is valid; patterns are not supported.
+func validationRules(schema *openAPISchema, info typeInfo, required bool) []string {
+	var rules []string
+	if required && (info.Kind == "string" || info.Kind == "slice" || info.Kind == "map") {
+		rules = append(rules, "required")
+	}
+	if schema == nil || schema.Ref != "" {
+		return rules
+	}
+	switch info.Kind {
+	case "string":
+		if schema.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *schema.MinLength))
+		}
+		if schema.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *schema.MaxLength))
+		}
+		switch schema.Format {
+		case "email":
+			rules = append(rules, "email")
+		case "uuid":
+			rules = append(rules, "uuid")
+		case "uri", "url":
+			rules = append(rules, "url")
+		}
+		var values []string
+		for _, value := range schema.Enum {
+			text := fmt.Sprint(value)
+			if text == "" || strings.ContainsAny(text, " ,'") {
+				values = nil
+				break
+			}
+			values = append(values, text)
+		}
+		if len(values) > 0 {
+			rules = append(rules, "oneof="+strings.Join(values, " "))
+		}
+	case "number":
+		if rule := boundRule(schema.Minimum, schema.ExclusiveMinimum, "gte", "gt"); rule != "" {
+			rules = append(rules, rule)
+		}
+		if rule := boundRule(schema.Maximum, schema.ExclusiveMaximum, "lte", "lt"); rule != "" {
+			rules = append(rules, rule)
+		}
+	case "slice":
+		if schema.MinItems != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *schema.MinItems))
+		}
+		if schema.MaxItems != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *schema.MaxItems))
+		}
+		if info.Struct {
+			rules = append(rules, "dive")
+		}
+	}
+	return rules
+}
+
+// boundRule handles both the 3.0 (boolean) and 3.1 (number) forms of
+// exclusiveMinimum and exclusiveMaximum.
+func boundRule(bound *float64, exclusive interface{}, inclusiveRule string, exclusiveRule string) string {
+	switch value := exclusive.(type) {
+	case bool:
+		if value && bound != nil {
+			return exclusiveRule + "=" + strconv.FormatFloat(*bound, 'f', -1, 64)
+		}
+	case int:
+		return exclusiveRule + "=" + strconv.Itoa(value)
+	case float64:
+		return exclusiveRule + "=" + strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if bound != nil {
+		return inclusiveRule + "=" + strconv.FormatFloat(*bound, 'f', -1, 64)
+	}
+	return ""
+}
+
+// checkConflicts rejects generated names that are already declared by hand
+// in the schema, controller or router packages.
+func (g *apiGenerator) checkConflicts(dir string) error {
+	generated := map[string][]string{apiSchemaDir: nil, apiControllerDir: nil, apiRouterDir: nil}
+	for _, t := range g.model.Types {
+		generated[apiSchemaDir] = append(generated[apiSchemaDir], t.Name)
+	}
+	for _, tag := range g.model.Tags {
+		generated[apiControllerDir] = append(generated[apiControllerDir], tag.Interface)
+		generated[apiRouterDir] = append(generated[apiRouterDir], tag.Router)
+	}
+	for packageDir, names := range generated {
+		declared, err := declaredIdentifiers(filepath.Join(dir, packageDir))
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if file, ok := declared[name]; ok {
+				return fmt.Errorf("%s is already declared in %s, rename the schema, operation or tag", name, filepath.Join(packageDir, file))
+			}
+		}
+	}
+	return nil
+}
+
+// declaredIdentifiers returns the top-level names declared by the
+// hand-written files of a package, with the file declaring them.
+func declaredIdentifiers(dir string) (map[string]string, error) {
+	declared := map[string]string{}
+	files, err := parseHandWrittenFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for name, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						declared[spec.Name.Name] = name
+					case *ast.ValueSpec:
+						for _, ident := range spec.Names {
+							declared[ident.Name] = name
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					declared[decl.Name.Name] = name
+				}
+			}
+		}
+	}
+	return declared, nil
+}
+
+// parseHandWrittenFiles parses the non-generated, non-test Go files of dir.
+func parseHandWrittenFiles(dir string) (map[string]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*ast.File{}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, ".gen.go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = file
+	}
+	return files, nil
+}
+
+// writeAPI writes the generated files, removes the ones of tags that are no
+// longer in the spec and adds stubs for the operations without controller
+// methods.
+func writeAPI(dir string, model *apiModel) error {
+	templates, err := template.New("api").ParseFS(templateFS, apiTemplatePath+"/*.tmpl")
+	if err != nil {
+		return err
+	}
+	execute := func(name string, data *apiModel) ([]byte, error) {
+		var buf bytes.Buffer
+		err := templates.ExecuteTemplate(&buf, name, data)
+		return buf.Bytes(), err
+	}
+	render := func(name string, data *apiModel) ([]byte, error) {
+		source, err := execute(name, data)
+		if err != nil {
+			return nil, err
+		}
+		return formatSource(name, source)
+	}
+	files := map[string]string{
+		filepath.Join(apiSchemaDir, "api.gen.go"):     "schema.gen.go.tmpl",
+		filepath.Join(apiControllerDir, "api.gen.go"): "controller_api.gen.go.tmpl",
+		apiRouterIndexFile:                            "router_api.gen.go.tmpl",
+	}
+	tagFiles := map[string]*apiTag{}
+	for _, tag := range model.Tags {
+		files[filepath.Join(apiControllerDir, tag.FileName+"_api.gen.go")] = "controller.gen.go.tmpl"
+		files[filepath.Join(apiRouterDir, tag.FileName+"_router.gen.go")] = "router.gen.go.tmpl"
+		tagFiles[filepath.Join(apiControllerDir, tag.FileName+"_api.gen.go")] = tag
+		tagFiles[filepath.Join(apiRouterDir, tag.FileName+"_router.gen.go")] = tag
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		data := *model
+		data.Tag = tagFiles[path]
+		source, err := render(files[path], &data)
+		if err != nil {
+			return err
+		}
+		if err := writeIfChanged(dir, path, source); err != nil {
+			return err
+		}
+	}
+
+	for _, pattern := range []string{filepath.Join(apiControllerDir, "*_api.gen.go"), filepath.Join(apiRouterDir, "*_router.gen.go")} {
+		stale, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range stale {
+			relPath, _ := filepath.Rel(dir, path)
+			if _, ok := files[relPath]; ok {
+				continue
+			}
+			if data, err := os.ReadFile(path); err == nil && strings.HasPrefix(string(data), apiGeneratedHeader) {
+				fmt.Printf("🗑️  %s\n", relPath)
+				check(os.Remove(path))
+				if strings.HasSuffix(path, "_api.gen.go") {
+					controller := strings.TrimSuffix(path, "_api.gen.go") + "_controller.go"
+					if fileExists(controller) {
+						relController, _ := filepath.Rel(dir, controller)
+						fmt.Printf("⚠️  %s serves operations that left the spec, delete it or its methods\n", relController)
+					}
+				}
+			}
+		}
+	}
+
+	return writeControllerStubs(dir, model, func(name string, tag *apiTag, operations []*apiOperation) ([]byte, error) {
+		data := *model
+		data.Tag, data.Operations = tag, operations
+		return execute(name, &data)
+	})
+}
+
+func formatSource(name string, source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w\n%s", name, err, source)
+	}
+	return formatted, nil
+}
+
+func writeIfChanged(dir string, path string, data []byte) error {
+	if current, err := os.ReadFile(filepath.Join(dir, path)); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	fmt.Printf("📝 %s\n", path)
+	return os.WriteFile(filepath.Join(dir, path), data, 0644)
+}
+
+// writeControllerStubs creates the controller of new tags and, for existing
+// controllers, a <tag>_controller_pending.go with the methods they miss.
+// Hand-written files are never rewritten.
+func writeControllerStubs(dir string, model *apiModel, render func(string, *apiTag, []*apiOperation) ([]byte, error)) error {
+	files, err := parseHandWrittenFiles(filepath.Join(dir, apiControllerDir))
+	if err != nil {
+		return err
+	}
+	types := map[string]bool{}
+	methods := map[string]bool{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						types[spec.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) == 1 {
+					methods[receiverType(decl.Recv.List[0].Type)+"."+decl.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, tag := range model.Tags {
+		if !types[tag.Controller] {
+			path := filepath.Join(apiControllerDir, tag.FileName+"_controller.go")
+			if regenerate {
+				fmt.Printf("⚠️  %s is missing, run without --regenerate to create it\n", tag.Controller)
+				continue
+			}
+			source, err := render("controller.go.tmpl", tag, tag.Operations)
+			if err == nil {
+				source, err = formatSource(path, source)
+			}
+			if err != nil {
+				return err
+			}
+			if err := writeIfChanged(dir, path, source); err != nil {
+				return err
+			}
+			continue
+		}
+		var missing []*apiOperation
+		for _, operation := range tag.Operations {
+			if !methods[tag.Controller+"."+operation.Name] {
+				missing = append(missing, operation)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if regenerate {
+			for _, operation := range missing {
+				fmt.Printf("⚠️  %s.%s is not implemented\n", tag.Controller, operation.Name)
+			}
+			continue
+		}
+		path := filepath.Join(apiControllerDir, tag.FileName+"_controller_pending.go")
+		var source []byte
+		if current, err := os.ReadFile(filepath.Join(dir, path)); err == nil {
+			stubs, err := render("controller_methods.go.tmpl", tag, missing)
+			if err != nil {
+				return err
+			}
+			source = append(current, stubs...)
+		} else if source, err = render("controller_pending.go.tmpl", tag, missing); err != nil {
+			return err
+		}
+		if source, err = formatSource(path, source); err != nil {
+			return err
+		}
+		if err := writeIfChanged(dir, path, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func receiverType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// qualifySchemaType prefixes the generated types of an expression with the
+// schema package, e.g. []Pet becomes []schema.Pet.
+func qualifySchemaType(expr string) string {
+	return reTypeIdent.ReplaceAllString(expr, "${1}schema.${2}")
+}
+
+// splitWords splits an identifier on separators and case changes:
+// "petStore", "pet-store" and "PetStore" are all pet, store.
+func splitWords(s string) []string {
+	var words []string
+	for _, part := range reNonAlnum.Split(s, -1) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// goName returns the exported Go name of an identifier from the spec.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "N" + name
+	}
+	return name
+}
+
+// lowerCamel returns the unexported Go name of an identifier.
+func lowerCamel(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return "n"
+	}
+	name := goName(s)
+	first := goName(words[0])
+	return strings.ToLower(first) + strings.TrimPrefix(name, first)
+}
+
+func typeDoc(name string, description string) string {
+	if line := firstLine(description); line != "" {
+		return name + " " + line
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
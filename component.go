@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var componentDryRun bool
+
+// componentChange is a file add component writes. Rendered is set when
+// Content is the file as the template renders it now, merged otherwise.
+type componentChange struct {
+	Name     string
+	Content  []byte
+	Mode     fs.FileMode
+	Verb     string
+	Rendered bool
+}
+
+// componentPlan is what adding a component does to a project: the files it
+// writes, what it leaves for the developer and the conflicts that prevent
+// it.
+type componentPlan struct {
+	Changes   []componentChange
+	Warnings  []string
+	Conflicts []string
+}
+
+// pinnedRevision returns the revision of the template the lock records, to
+// render it as it was when the project was generated: that of its
+// repository or module, or for a built-in template the version of beginning
+// which generated the project. Template directories have none, nor do
+// built-in templates of the running version. It is not known, and ok false,
+// when a development build of beginning generated the project.
+func (l *projectLock) pinnedRevision() (revision string, ok bool) {
+	switch l.Template.Source.Kind {
+	case sourceKindGit, sourceKindModule:
+		return strings.TrimSuffix(l.Template.Revision, "-dirty"), true
+	case sourceKindBuiltin:
+		// Builds of a checkout are (devel), or stamped +dirty when it has
+		// changes: no module has their templates
+		if l.Beginning == "" || l.Beginning == "(devel)" || strings.Contains(l.Beginning, "+") {
+			return "", false
+		}
+		if l.Beginning != beginningVersion() {
+			return l.Beginning, true
+		}
+	}
+	return "", true
+}
+
+// copyValues returns values with a copy of its components and variables,
+// which rendering fills in.
+func copyValues(values Values) Values {
+	values.Components = append([]string(nil), values.Components...)
+	vars := map[string]string{}
+	for name, value := range values.Vars {
+		vars[name] = value
+	}
+	values.Vars = vars
+	return values
+}
+
+// planComponent renders the template of the project in root with the values
+// before and after adding a component and compares both with the project:
+// files the component adds are created, those it changes are updated when
+// the project left them as rendered and merged otherwise.
+func planComponent(root string, t *projectTemplate, before Values, after Values) (*componentPlan, error) {
+	oldDir, err := renderTemporary(root, t, copyValues(before))
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(oldDir)
+	newDir, err := renderTemporary(root, t, copyValues(after))
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(newDir)
+
+	names, err := projectFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+	plan := &componentPlan{}
+	rendered := map[string]bool{}
+	for _, name := range names {
+		rendered[name] = true
+		newPath := filepath.Join(newDir, filepath.FromSlash(name))
+		info, err := os.Stat(newPath)
+		if err != nil {
+			return nil, err
+		}
+		newContent, err := os.ReadFile(newPath)
+		if err != nil {
+			return nil, err
+		}
+		oldContent, inOld, err := readOptional(filepath.Join(oldDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		projectContent, inProject, err := readOptional(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+
+		change := componentChange{Name: name, Content: newContent, Mode: info.Mode().Perm(), Rendered: true}
+		switch {
+		case inProject && bytes.Equal(projectContent, newContent):
+		case !inOld && !inProject:
+			change.Verb = "create"
+			plan.Changes = append(plan.Changes, change)
+		case !inOld:
+			plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("%s: the project has a file of its own there", name))
+		case bytes.Equal(oldContent, newContent):
+		case !inProject:
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s was deleted from the project, the changes of the component to it are left out", name))
+		case bytes.Equal(projectContent, oldContent):
+			change.Verb = "update"
+			plan.Changes = append(plan.Changes, change)
+		default:
+			var merged []byte
+			var conflicts []string
+			switch path.Ext(name) {
+			case ".go":
+				merged, conflicts = mergeGo(name, oldContent, newContent, projectContent)
+			case ".yaml", ".yml":
+				merged, conflicts = mergeYAML(name, oldContent, newContent, projectContent)
+			default:
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s was changed in the project, add the changes of the component by hand ('beginning diff %s' shows them)", name, name))
+				continue
+			}
+			if len(conflicts) > 0 {
+				plan.Conflicts = append(plan.Conflicts, conflicts...)
+				continue
+			}
+			change.Verb, change.Content, change.Rendered = "merge", merged, false
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	oldNames, err := projectFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range oldNames {
+		if !rendered[name] && fileExists(filepath.Join(root, filepath.FromSlash(name))) {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s is no part of the template with the component, remove it if it is not needed anymore", name))
+		}
+	}
+	return plan, nil
+}
+
+// readOptional reads the file at path, reporting whether it exists.
+func readOptional(path string) ([]byte, bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	return content, err == nil, err
+}
+
+// rerunSetup runs the steps of setupProject again which depend on the code:
+// the swagger docs, go mod tidy and wire, then adds the license header to
+// the files they generated and runs the post hooks of the template chain
+// with values, as create does. Unlike during create a failing step does not
+// stop the others, the files are in place already.
+func rerunSetup(root string, t *projectTemplate, values Values) error {
+	steps := []struct {
+		File    string
+		Command string
+	}{
+		{"bin/swagger.sh", "./bin/swagger.sh"},
+		{"go.mod", "go mod tidy"},
+		{"bin/wire.sh", "./bin/wire.sh"},
+	}
+	return inDir(root, func() error {
+		if fileExists("bin") {
+			if err := runCommand("chmod +x bin/*"); err != nil {
+				return err
+			}
+		}
+		for _, step := range steps {
+			if !fileExists(step.File) {
+				continue
+			}
+			if err := runCommand(step.Command); err != nil {
+				fmt.Printf("⚠️  %s failed (%v), fix the errors above and run it again\n", step.Command, err)
+			}
+		}
+		if values.LicenseHeaders {
+			partials, err := t.partials()
+			if err != nil {
+				return err
+			}
+			_, header, err := partials.license(values)
+			if err != nil {
+				return err
+			}
+			if _, _, err := addLicenseHeaders(".", header); err != nil {
+				return err
+			}
+		}
+		if err := t.runPostHooks(values); err != nil {
+			fmt.Printf("⚠️  %v, fix the errors above and run the hooks left again\n", err)
+		}
+		return nil
+	})
+}
+
+func runAddComponent(cmd *cobra.Command, args []string) {
+	component := args[0]
+	root, lock, err := findProjectLock(projectDir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if lock.Values.Has(component) {
+		fmt.Printf("❌ %s has the %s component already\n", lock.Values.RepoName, component)
+		os.Exit(1)
+	}
+
+	// The template as it was when the project was generated, so that its
+	// render before the component is the project as generated
+	revision, ok := lock.pinnedRevision()
+	if !ok {
+		fmt.Printf("⚠️  %s was generated by a development build of beginning (%s), using the template %s of this one: check the changes it makes\n",
+			lock.Values.RepoName, lock.Beginning, lock.Template.Ref)
+	}
+	t, err := lock.Template.locate(revision)
+	if err != nil {
+		fmt.Printf("❌ Error locating template %s: %v\n", lock.Template.Ref, err)
+		os.Exit(1)
+	}
+	if !t.hasComponent(component) {
+		fmt.Printf("❌ Component '%s' not found for template '%s'!\n", component, lock.Template.Ref)
+		if components := t.components(); len(components) > 0 {
+			fmt.Printf("Available components: %s\n", strings.Join(components, ", "))
+		}
+		os.Exit(1)
+	}
+	for _, enabled := range lock.Values.Components {
+		if !t.hasComponent(enabled) {
+			fmt.Printf("❌ Template %s has no component %s anymore\n", lock.Template.Ref, enabled)
+			os.Exit(1)
+		}
+	}
+
+	before := copyValues(lock.Values)
+	after := copyValues(lock.Values)
+	after.Components = append(after.Components, component)
+	plan, err := planComponent(root, t, before, after)
+	if err != nil {
+		fmt.Printf("❌ Error rendering template: %v\n", err)
+		os.Exit(1)
+	}
+	if len(plan.Conflicts) > 0 {
+		fmt.Printf("❌ Cannot add the %s component safely, the project diverged from the template:\n", component)
+		for _, conflict := range plan.Conflicts {
+			fmt.Printf("  - %s\n", conflict)
+		}
+		fmt.Printf("Nothing was changed. Add the files of _components/%s of template %s by hand,\n", component, lock.Template.Ref)
+		fmt.Println("'beginning diff' shows how the project differs from the template.")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Adding component %s to %s\n", component, root)
+	for _, change := range plan.Changes {
+		fmt.Printf("  %-6s %s\n", change.Verb, change.Name)
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if componentDryRun {
+		return
+	}
+
+	// The files as generated, the only ones whose hash the lock follows
+	generated := map[string]bool{}
+	for name, sum := range lock.Files {
+		if current, err := fileHash(filepath.Join(root, filepath.FromSlash(name))); err == nil && current == sum {
+			generated[name] = true
+		}
+	}
+	existing, err := projectFiles(root)
+	check(err)
+	existed := map[string]bool{}
+	for _, name := range existing {
+		existed[name] = true
+	}
+
+	merged := map[string]bool{}
+	for _, change := range plan.Changes {
+		target := filepath.Join(root, filepath.FromSlash(change.Name))
+		check(os.MkdirAll(filepath.Dir(target), 0755))
+		check(os.WriteFile(target, change.Content, change.Mode))
+		if !change.Rendered {
+			merged[change.Name] = true
+		}
+	}
+
+	// The docs, the module and the injection follow the new code, the
+	// generated files get the license header and the hooks of the template
+	// run, as during create
+	check(rerunSetup(root, t, after))
+
+	// Record the component, and the files as generated now: those the
+	// project had left as generated or did not have, the merged ones
+	// keeping the hash of their first generation
+	files, err := projectFiles(root)
+	check(err)
+	present := map[string]bool{}
+	for _, name := range files {
+		present[name] = true
+		if merged[name] || (existed[name] && !generated[name]) {
+			continue
+		}
+		sum, err := fileHash(filepath.Join(root, filepath.FromSlash(name)))
+		check(err)
+		lock.Files[name] = sum
+	}
+	for name := range generated {
+		if !present[name] {
+			delete(lock.Files, name)
+		}
+	}
+	lock.Values.Components = after.Components
+	if err := lock.write(root); err != nil {
+		fmt.Printf("❌ Error writing %s: %v\n", lockFileName, err)
+		os.Exit(1)
+	}
+
+	if component == "auth" && apiRecordedSource(filepath.Join(root, apiRouterIndexFile)) != "" {
+		fmt.Println("ℹ️  Run 'beginning add api --regenerate' to protect the operations of the API with the auth middleware")
+	}
+	fmt.Printf("✅ Component %s added to %s\n", component, lock.Values.RepoName)
+}
+
+// completeComponentsToAdd completes the components of the template of the
+// project which it does not have yet. Templates are not fetched for it:
+// only the built-in ones and template directories complete.
+func completeComponentsToAdd(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	dir, _ := cmd.Flags().GetString("dir")
+	_, lock, err := findProjectLock(dir)
+	if err != nil || (lock.Template.Source.Kind != sourceKindBuiltin && lock.Template.Source.Kind != sourceKindDir) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	t, err := lock.Template.locate("")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, component := range t.components() {
+		if !lock.Values.Has(component) {
+			completions = append(completions, component+"\t"+t.componentDescription(component))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// componentTemplates is a template whose component c adds a file and
+// changes two of the template.
+var componentTemplates = fstest.MapFS{
+	"template/demo/app.go.tmpl": mapFile(`package demo
+
+type App struct {
+	Server Server
+{{- if .Has "c"}}
+	C      C
+{{- end}}
+}
+`),
+	"template/demo/config.yaml.tmpl": mapFile(`server:
+  addr: ":3000"
+{{- if .Has "c"}}
+c:
+  enabled: true
+{{- end}}
+`),
+	"template/demo/notes.txt.tmpl":     mapFile(`{{if .Has "c"}}c{{else}}none{{end}}`),
+	"template/demo/_components/c/c.go": mapFile("package demo\n\ntype C struct{}\n"),
+}
+
+func TestPlanComponent(t *testing.T) {
+	tests := map[string]struct {
+		// project are the files changed in the project as generated
+		project   map[string]string
+		changes   []string
+		warnings  int
+		conflicts []string
+	}{
+		"as generated": {
+			changes: []string{"update app.go", "create c.go", "update config.yaml", "update notes.txt"},
+		},
+		"changed": {
+			project: map[string]string{
+				"app.go":      "package demo\n\ntype App struct {\n\tServer Server\n\tOrders Orders\n}\n",
+				"config.yaml": "server:\n  addr: \":8080\"\n",
+				"notes.txt":   "mine",
+			},
+			changes:  []string{"merge app.go", "create c.go", "merge config.yaml"},
+			warnings: 1,
+		},
+		"conflicts": {
+			project: map[string]string{
+				"app.go":      "package demo\n\ntype App struct {\n\tServer Server\n\tC      *C\n}\n",
+				"c.go":        "package demo\n\ntype C int\n",
+				"config.yaml": "server:\n  addr: \":3000\"\nc: false\n",
+			},
+			conflicts: []string{
+				"app.go: type App: the project has its own C",
+				"c.go: the project has a file of its own there",
+				"config.yaml: c: the project has its own value",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			useBuiltinTemplates(t, componentTemplates)
+			tmpl, err := resolveTemplate("demo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := Values{RepoName: "demo", GoVersion: minGoVersion}
+			after := copyValues(before)
+			after.Components = []string{"c"}
+
+			root := t.TempDir()
+			if _, err := tmpl.render(root, copyValues(before)); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range test.project {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			files := map[string]string{}
+			names, err := projectFiles(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range names {
+				content, err := os.ReadFile(filepath.Join(root, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				files[name] = string(content)
+			}
+
+			plan, err := planComponent(root, tmpl, before, after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var changes []string
+			for _, change := range plan.Changes {
+				changes = append(changes, change.Verb+" "+change.Name)
+			}
+			if len(test.conflicts) == 0 && !reflect.DeepEqual(changes, test.changes) {
+				t.Errorf("changes %q, want %q", changes, test.changes)
+			}
+			if len(plan.Warnings) != test.warnings {
+				t.Errorf("warnings %q, want %d", plan.Warnings, test.warnings)
+			}
+			got := strings.Join(plan.Conflicts, "\n")
+			for _, want := range test.conflicts {
+				if !strings.Contains(got, want) {
+					t.Errorf("conflicts %q, want %q", plan.Conflicts, want)
+				}
+			}
+			if len(test.conflicts) == 0 && len(plan.Conflicts) > 0 {
+				t.Errorf("conflicts %q, want none", plan.Conflicts)
+			}
+
+			// Planning leaves the project as it is
+			for name, want := range files {
+				content, err := os.ReadFile(filepath.Join(root, name))
+				if err != nil || string(content) != want {
+					t.Errorf("%s was changed to %q (%v)", name, content, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPinnedRevision(t *testing.T) {
+	tests := []struct {
+		kind      string
+		beginning string
+		revision  string
+		want      string
+		ok        bool
+	}{
+		{sourceKindBuiltin, "v1.0.0", "", "v1.0.0", true},
+		{sourceKindBuiltin, "(devel)", "", "", false},
+		{sourceKindBuiltin, "v1.0.1-0.20261019023236-77030d83f4c6+dirty", "", "", false},
+		{sourceKindGit, "(devel)", "abc123-dirty", "abc123", true},
+		{sourceKindModule, "v1.0.0", "v0.3.0", "v0.3.0", true},
+		{sourceKindDir, "v1.0.0", "", "", true},
+	}
+	for _, test := range tests {
+		lock := &projectLock{Beginning: test.beginning, Template: lockTemplate{Revision: test.revision, Source: templateSource{Kind: test.kind}}}
+		if got, ok := lock.pinnedRevision(); got != test.want || ok != test.ok {
+			t.Errorf("%s generated by %s: pinnedRevision() = %q, %v, want %q, %v", test.kind, test.beginning, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+// serveBeginning serves the built-in templates files as beginning version
+// from a module proxy in a temporary directory.
+func serveBeginning(t *testing.T, version string, files fstest.MapFS) {
+	t.Helper()
+	proxy := t.TempDir()
+	dir := filepath.Join(proxy, filepath.FromSlash(beginningModule), "@v")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module " + beginningModule + "\n"
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("list", version+"\n")
+	write(version+".info", `{"Version":"`+version+`","Time":"2026-01-01T00:00:00Z"}`)
+	write(version+".mod", goMod)
+
+	archive, err := os.Create(filepath.Join(dir, version+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(archive)
+	files["go.mod"] = mapFile(goMod)
+	for name, file := range files {
+		f, err := w.Create(beginningModule + "@" + version + "/" + name)
+		if err == nil {
+			_, err = f.Write(file.Data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+}
+
+func TestAddComponentAfterUpgrade(t *testing.T) {
+	released := fstest.MapFS{}
+	for name, file := range componentTemplates {
+		released[name] = file
+	}
+	serveBeginning(t, "v1.0.0", released)
+
+	// The templates changed since v1.0.0, which generated the project
+	upgraded := fstest.MapFS{}
+	for name, file := range componentTemplates {
+		upgraded[name] = file
+	}
+	upgraded["template/demo/notes.txt.tmpl"] = mapFile(`{{if .Has "c"}}c{{else}}nothing{{end}}`)
+	upgraded["template/demo/app.go.tmpl"] = mapFile("package demo\n\ntype App struct {\n\tServer Server\n}\n")
+	useBuiltinTemplates(t, upgraded)
+
+	lock := &projectLock{
+		Beginning: "v1.0.0",
+		Template:  lockTemplate{Ref: "demo", Name: "demo", Source: templateSource{Kind: sourceKindBuiltin, Location: "demo"}},
+		Values:    Values{RepoName: "demo", GoVersion: minGoVersion},
+	}
+	revision, ok := lock.pinnedRevision()
+	if !ok {
+		t.Fatalf("pinnedRevision() of v1.0.0 is unknown")
+	}
+	tmpl, err := lock.Template.locate(revision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if _, err := tmpl.render(root, copyValues(lock.Values)); err != nil {
+		t.Fatal(err)
+	}
+
+	after := copyValues(lock.Values)
+	after.Components = []string{"c"}
+	plan, err := planComponent(root, tmpl, lock.Values, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	for _, change := range plan.Changes {
+		changes = append(changes, change.Verb+" "+change.Name)
+	}
+	// As the project was generated, none of the changes since are in
+	want := []string{"update app.go", "create c.go", "update config.yaml", "update notes.txt"}
+	if !reflect.DeepEqual(changes, want) || len(plan.Warnings) > 0 || len(plan.Conflicts) > 0 {
+		t.Errorf("changes %q, warnings %q, conflicts %q, want changes %q", changes, plan.Warnings, plan.Conflicts, want)
+	}
+}
+
+func TestRerunSetupHooks(t *testing.T) {
+	useBuiltinTemplates(t, chainTemplates)
+	child, err := resolveTemplate("child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := rerunSetup(root, child, Values{RepoName: "demo"}); err != nil {
+		t.Fatal(err)
+	}
+	// The hooks of the chain run until the failing one, which is a warning
+	log, err := os.ReadFile(filepath.Join(root, "hooks.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "parent\ndemo\n"; string(log) != want {
+		t.Errorf("hooks.log = %q, want %q", log, want)
+	}
+}
@@ -0,0 +1,689 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes of beginning diff, those of diff(1).
+const (
+	diffExitDifferent = 1
+	diffExitError     = 2
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk.
+const diffContext = 3
+
+// beginningModule is the module of beginning, which has the built-in
+// templates of each of its versions.
+const beginningModule = "github.com/zeroxsolutions/beginning"
+
+var (
+	diffTemplateVersion string
+	diffSetVars         map[string]string
+	diffIgnore          []string
+	diffStat            bool
+)
+
+// locate finds the template a project was generated from again, at version
+// when it is given: a git revision or a module version, that of beginning
+// for built-in templates. Without one it is the template as create would use
+// it today, the latest commit of a branch or version of a module included.
+func (l lockTemplate) locate(version string) (*projectTemplate, error) {
+	source := l.Source
+	switch source.Kind {
+	case sourceKindBuiltin:
+		if version == "" {
+			return resolveTemplate(source.Location)
+		}
+		return locateBuiltinVersion(source, version)
+	case sourceKindDir:
+		if version != "" {
+			return nil, fmt.Errorf("the template directory %s has no versions, check out %s there instead of --template-version", source.Location, version)
+		}
+	case sourceKindGit:
+		if version != "" {
+			url, _, subdir, _ := parseGitTemplateRef("git+" + source.Location)
+			if subdir != "" {
+				url += "//" + subdir
+			}
+			source.Location = url + "#" + version
+		}
+	case sourceKindModule:
+		if version != "" {
+			modulePath, _, _ := strings.Cut(source.Location, "@")
+			source.Location = modulePath + "@" + version
+		}
+	default:
+		return nil, fmt.Errorf("unknown template source kind %q in %s", source.Kind, lockFileName)
+	}
+
+	checkout, err := source.checkout(true)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(checkout, filepath.FromSlash(l.Path))
+	if !isDir(dir) {
+		return nil, fmt.Errorf("%s has no template %s", source.Location, l.Path)
+	}
+	id := source.Kind + ":" + source.Location
+	if l.Path != "" {
+		id += "//" + l.Path
+	}
+	t := &projectTemplate{
+		Name: l.Ref, ID: id, Dir: dir, FS: os.DirFS(dir), Root: ".",
+		Source: source, Path: l.Path,
+	}
+	if err := t.resolve(nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// locateBuiltinVersion finds the built-in template of source in version of
+// beginning, downloaded as a module. The template keeps the tree of that
+// version, for its built-in partials.
+func locateBuiltinVersion(source templateSource, version string) (*projectTemplate, error) {
+	module := beginningModule + "@" + version
+	checkout, err := downloadModule(module)
+	if err != nil {
+		return nil, err
+	}
+	root := path.Join("template", source.Location)
+	if !isDir(filepath.Join(checkout, filepath.FromSlash(root))) {
+		return nil, fmt.Errorf("beginning %s has no built-in template %s", version, source.Location)
+	}
+	t := &projectTemplate{
+		Name: source.Location, ID: sourceKindModule + ":" + module + "//" + root,
+		FS: os.DirFS(checkout), Root: root, Source: source,
+	}
+	if err := t.resolve(nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// matchPath reports whether the slash separated path name is pattern, is
+// below the directory pattern or matches pattern as a glob.
+func matchPath(pattern string, name string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" || pattern == "." || name == pattern || strings.HasPrefix(name, pattern+"/") {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// diffEdit is a line of a diff: ' ' when both sides have it, '-' when only
+// the rendered template has it, '+' when only the project has it.
+type diffEdit struct {
+	Op   byte
+	Line string
+}
+
+// splitLines splits content after each newline, so that a missing newline at
+// the end of a file is a change too.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b, with the
+// algorithm of Myers, "An O(ND) Difference Algorithm and Its Variations".
+func diffLines(a []string, b []string) []diffEdit {
+	// The common prefix and suffix are no part of the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	var edits []diffEdit
+	for _, line := range a[:prefix] {
+		edits = append(edits, diffEdit{' ', line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, diffEdit{' ', line})
+	}
+	return edits
+}
+
+func myers(a []string, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v before step d, for the diagonals -d-1 to d+1
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersEdits(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+// myersEdits walks the trace of myers back from the end of a and b.
+func myersEdits(trace [][]int, a []string, b []string) []diffEdit {
+	var edits []diffEdit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, diffEdit{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, diffEdit{'+', b[y-1]})
+			y--
+		} else {
+			edits = append(edits, diffEdit{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, diffEdit{' ', a[x-1]})
+		x--
+		y--
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// writeUnifiedDiff writes the hunks of edits, each change with diffContext
+// lines around it.
+func writeUnifiedDiff(w io.Writer, edits []diffEdit) {
+	inHunk := make([]bool, len(edits))
+	for i, edit := range edits {
+		if edit.Op == ' ' {
+			continue
+		}
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(edits) {
+				inHunk[j] = true
+			}
+		}
+	}
+
+	aLine, bLine := 0, 0
+	for i := 0; i < len(edits); {
+		if !inHunk[i] {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+		end := i
+		aCount, bCount := 0, 0
+		for ; end < len(edits) && inHunk[end]; end++ {
+			if edits[end].Op != '+' {
+				aCount++
+			}
+			if edits[end].Op != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+		for _, edit := range edits[i:end] {
+			fmt.Fprintf(w, "%c%s", edit.Op, edit.Line)
+			if !strings.HasSuffix(edit.Line, "\n") {
+				fmt.Fprint(w, "\n\\ No newline at end of file\n")
+			}
+		}
+		aLine += aCount
+		bLine += bCount
+		i = end
+	}
+}
+
+// hunkRange formats the lines of a side of a hunk: those after line before,
+// or before itself when the side has none.
+func hunkRange(before int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// fileDiff is how a file of the project differs from the rendered template.
+type fileDiff struct {
+	Name    string
+	Binary  bool
+	Missing bool
+	Edits   []diffEdit
+	Added   int
+	Deleted int
+}
+
+func newFileDiff(name string, rendered []byte, project []byte, missing bool) *fileDiff {
+	diff := &fileDiff{Name: name, Missing: missing}
+	if bytes.IndexByte(rendered, 0) >= 0 || bytes.IndexByte(project, 0) >= 0 {
+		diff.Binary = true
+		return diff
+	}
+	diff.Edits = diffLines(splitLines(rendered), splitLines(project))
+	for _, edit := range diff.Edits {
+		switch edit.Op {
+		case '+':
+			diff.Added++
+		case '-':
+			diff.Deleted++
+		}
+	}
+	return diff
+}
+
+func (d *fileDiff) write(w io.Writer) {
+	project := "b/" + d.Name
+	if d.Missing {
+		project = "/dev/null"
+	}
+	if d.Binary {
+		fmt.Fprintf(w, "Binary files a/%s and %s differ\n", d.Name, project)
+		return
+	}
+	fmt.Fprintf(w, "--- a/%s\n+++ %s\n", d.Name, project)
+	writeUnifiedDiff(w, d.Edits)
+}
+
+// writeDiffStat writes the changed lines of each file and their total, as
+// git diff --stat does.
+func writeDiffStat(w io.Writer, diffs []*fileDiff) {
+	const barWidth = 40
+	nameWidth, most, added, deleted := 0, 0, 0, 0
+	for _, diff := range diffs {
+		if len(diff.Name) > nameWidth {
+			nameWidth = len(diff.Name)
+		}
+		if changes := diff.Added + diff.Deleted; changes > most {
+			most = changes
+		}
+		added += diff.Added
+		deleted += diff.Deleted
+	}
+	countWidth := len(fmt.Sprint(most))
+	for _, diff := range diffs {
+		if diff.Binary {
+			fmt.Fprintf(w, " %-*s | %*s\n", nameWidth, diff.Name, countWidth, "Bin")
+			continue
+		}
+		plus, minus := diff.Added, diff.Deleted
+		if most > barWidth {
+			plus = (plus*barWidth + most - 1) / most
+			minus = (minus*barWidth + most - 1) / most
+		}
+		fmt.Fprintf(w, " %-*s | %*d %s%s\n", nameWidth, diff.Name, countWidth, diff.Added+diff.Deleted,
+			strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+	fmt.Fprintf(w, " %d %s changed, %d %s(+), %d %s(-)\n",
+		len(diffs), plural(len(diffs), "file", "files"),
+		added, plural(added, "insertion", "insertions"),
+		deleted, plural(deleted, "deletion", "deletions"))
+}
+
+func plural(count int, one string, many string) string {
+	if count == 1 {
+		return one
+	}
+	return many
+}
+
+// renderLocked renders the template of lock with values into a temporary
+// directory, as create did without running the setup of the project and
+// the hooks, and returns the directory. The render goes to disk rather than
+// memory for go mod tidy and the dedupe of the workspace, which work on
+// files. A failing tidy is reported to errOut, in one line.
+func renderLocked(errOut io.Writer, root string, lock *projectLock, values Values) (string, error) {
+	t, err := lock.Template.locate(diffTemplateVersion)
+	if err != nil {
+		return "", err
+	}
+	for _, component := range values.Components {
+		if !t.hasComponent(component) {
+			return "", fmt.Errorf("template %s has no component %s anymore", lock.Template.Ref, component)
+		}
+	}
+	dir, err := renderTemporary(root, t, values)
+	if err != nil {
+		return "", err
+	}
+	if fileExists(filepath.Join(dir, "go.mod")) {
+		if err := tidyRendered(dir, root, lock); err != nil {
+			fmt.Fprintf(errOut, "⚠️  %v, go.mod is compared as rendered\n", err)
+		}
+	}
+	return dir, nil
+}
+
+// renderTemporary renders t with values into a temporary directory for the
+// project in root, the license and the dedupe of its workspace included, and
+// returns the directory.
+func renderTemporary(root string, t *projectTemplate, values Values) (string, error) {
+	if required := t.goVersion(); compareGoVersions(values.GoVersion, required) < 0 {
+		values.GoVersion = required
+	}
+	if err := t.applyVariables(&values); err != nil {
+		return "", err
+	}
+	partials, err := t.partials()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "beginning-render-")
+	if err != nil {
+		return "", err
+	}
+	if _, err := t.renderWith(dir, values, partials); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if values.License != "" {
+		if _, _, err := writeLicense(dir, values, partials); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	if ws := findWorkspace(filepath.Dir(root)); ws != nil && !t.hasFile("go.work.tmpl") {
+		if err := ws.dedupe(dir); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// tidyRendered runs go mod tidy on the rendered module, as create did, from
+// the module cache only: the go.sum of the project is borrowed for it, and
+// the requirements of the project the template does not pin itself. The Go
+// files the hooks generated, the Swagger docs for one, are borrowed too, as
+// the lock records them, for the packages the rendered code imports.
+func tidyRendered(dir string, root string, lock *projectLock) error {
+	for name := range lock.Files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if path.Ext(name) != ".go" || fileExists(target) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+		defer os.Remove(target)
+	}
+
+	rendered, err := goModRequires(dir)
+	if err != nil {
+		return err
+	}
+	project, err := goModRequires(root)
+	if err != nil {
+		return err
+	}
+	args := []string{"mod", "edit"}
+	for modulePath, version := range project {
+		if _, ok := rendered[modulePath]; !ok {
+			args = append(args, "-require="+modulePath+"@"+version)
+		}
+	}
+	if len(args) > 2 {
+		if err := goCommand(dir, args...); err != nil {
+			return err
+		}
+	}
+
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err == nil {
+		if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644); err != nil {
+			return err
+		}
+		defer os.Remove(filepath.Join(dir, "go.sum"))
+	}
+	return goCommand(dir, "mod", "tidy")
+}
+
+// goModRequires returns the version of each module go.mod in dir requires.
+func goModRequires(dir string) (map[string]string, error) {
+	cmd := exec.Command("go", "mod", "edit", "-json")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go mod edit -json in %s: %w", dir, err)
+	}
+	var goMod struct {
+		Require []struct {
+			Path    string
+			Version string
+		}
+	}
+	if err := json.Unmarshal(output, &goMod); err != nil {
+		return nil, err
+	}
+	requires := map[string]string{}
+	for _, require := range goMod.Require {
+		requires[require.Path] = require.Version
+	}
+	return requires, nil
+}
+
+// goCommand runs the go tool in dir offline, outside of any workspace.
+func goCommand(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go %s: %s", strings.Join(args, " "), goError(output, err))
+	}
+	return nil
+}
+
+// goError returns the first error the go tool wrote to output as one line:
+// its progress lines are skipped and the lines indented under the error
+// joined to it. It is err when the output has none.
+func goError(output []byte, err error) string {
+	message := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+		case line[0] == ' ' || line[0] == '\t':
+			if message != "" {
+				message += " " + strings.TrimSpace(line)
+			}
+		case strings.HasPrefix(line, "go: downloading "), strings.HasPrefix(line, "go: finding "), strings.HasPrefix(line, "go: extracting "):
+		case message != "":
+			return message
+		default:
+			message = strings.TrimPrefix(line, "go: ")
+		}
+	}
+	if message == "" {
+		return err.Error()
+	}
+	return message
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	if code := diffWorkingProject(cmd.OutOrStdout(), cmd.ErrOrStderr(), args); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// diffWorkingProject compares the project of the working directory, or the
+// paths of it, with a fresh render of its template, writes the differences
+// to out, the warnings to errOut, and returns the exit code.
+func diffWorkingProject(out io.Writer, errOut io.Writer, args []string) int {
+	root, lock, err := findProjectLock(".")
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return diffExitError
+	}
+
+	// Paths are relative to the working directory, like those of git diff
+	var paths []string
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		check(err)
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			fmt.Printf("❌ %s is outside of the project %s\n", arg, root)
+			return diffExitError
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	for _, pattern := range append(paths, diffIgnore...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fmt.Printf("❌ Invalid pattern %q: %v\n", pattern, err)
+			return diffExitError
+		}
+	}
+
+	values := copyValues(lock.Values)
+	if err := applyDiffSet(&values, diffSetVars); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return diffExitError
+	}
+
+	dir, err := renderLocked(errOut, root, lock, values)
+	if err != nil {
+		fmt.Printf("❌ Error rendering template: %v\n", err)
+		return diffExitError
+	}
+	diffs, err := diffProject(dir, root, paths)
+	os.RemoveAll(dir)
+	if err != nil {
+		fmt.Printf("❌ Error comparing the project: %v\n", err)
+		return diffExitError
+	}
+	if len(diffs) == 0 {
+		return 0
+	}
+	if diffStat {
+		writeDiffStat(out, diffs)
+	} else {
+		for _, diff := range diffs {
+			diff.write(out)
+		}
+	}
+	return diffExitDifferent
+}
+
+// applyDiffSet overrides values with those of --set: the values recorded in
+// .beginning.yaml by their name, e.g. ModuleName or Year, and the variables
+// of the template otherwise. The components are added with add component.
+func applyDiffSet(values *Values, set map[string]string) error {
+	recorded := map[string]bool{}
+	valuesType := reflect.TypeOf(Values{})
+	for i := 0; i < valuesType.NumField(); i++ {
+		recorded[valuesType.Field(i).Tag.Get("yaml")] = true
+	}
+	for name, value := range set {
+		switch {
+		case name == "Components" || name == "Vars":
+			return fmt.Errorf("--set %s: only variables and single values can be set, add components with 'beginning add component'", name)
+		case recorded[name]:
+			node := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: name},
+				{Kind: yaml.ScalarNode, Value: value},
+			}}
+			if err := node.Decode(values); err != nil {
+				return fmt.Errorf("--set %s=%s: %w", name, value, err)
+			}
+		default:
+			values.Vars[name] = value
+		}
+	}
+	return nil
+}
+
+// diffProject compares the rendered files in dir with those of the project
+// in root, keeping the files matching one of paths, all when there is none,
+// and none of the ignore globs, which also match base names.
+func diffProject(dir string, root string, paths []string) ([]*fileDiff, error) {
+	rendered, err := projectFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []*fileDiff
+	for _, name := range rendered {
+		selected := len(paths) == 0
+		for _, pattern := range paths {
+			selected = selected || matchPath(pattern, name)
+		}
+		for _, pattern := range diffIgnore {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok || matchPath(pattern, name) {
+				selected = false
+			}
+		}
+		if !selected {
+			continue
+		}
+
+		want, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		got, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		missing := os.IsNotExist(err)
+		if err != nil && !missing {
+			return nil, err
+		}
+		if !missing && bytes.Equal(want, got) {
+			continue
+		}
+		diffs = append(diffs, newFileDiff(name, want, got, missing))
+	}
+	return diffs, nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// numbered returns the lines 1 to n, with those of replace swapped in.
+func numbered(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	return b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		// want is the edit script, the lines without their newline
+		want []string
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", []string{" a", " b", " c"}},
+		{"", "x\ny\n", []string{"+x", "+y"}},
+		{"x\ny\n", "", []string{"-x", "-y"}},
+		{"a\nb\nc\n", "a\nx\nc\n", []string{" a", "-b", "+x", " c"}},
+		{"a\nb\nc\nd\n", "b\nc\ne\n", []string{"-a", " b", " c", "-d", "+e"}},
+		{"x\n", "x", []string{"-x", "+x"}},
+		// The example of Myers, 5 changes at least
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", nil},
+	}
+	for _, test := range tests {
+		edits := diffLines(splitLines([]byte(test.a)), splitLines([]byte(test.b)))
+		var script []string
+		var a, b strings.Builder
+		changes := 0
+		for _, edit := range edits {
+			script = append(script, string(edit.Op)+strings.TrimSuffix(edit.Line, "\n"))
+			if edit.Op != '+' {
+				a.WriteString(edit.Line)
+			}
+			if edit.Op != '-' {
+				b.WriteString(edit.Line)
+			}
+			if edit.Op != ' ' {
+				changes++
+			}
+		}
+		if a.String() != test.a || b.String() != test.b {
+			t.Errorf("diffLines(%q, %q) = %q, which is not an edit script between them", test.a, test.b, script)
+		}
+		if test.want == nil {
+			if changes != 5 {
+				t.Errorf("diffLines(%q, %q) = %q, want 5 changes", test.a, test.b, script)
+			}
+		} else if !reflect.DeepEqual(script, test.want) {
+			t.Errorf("diffLines(%q, %q) = %q, want %q", test.a, test.b, script, test.want)
+		}
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want string
+	}{
+		"first line": {
+			numbered(10, nil), numbered(10, map[int]string{1: "one"}),
+			"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n",
+		},
+		"last line": {
+			numbered(10, nil), numbered(10, map[int]string{10: "ten"}),
+			"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		"merged hunks": {
+			numbered(20, nil), numbered(20, map[int]string{5: "five", 11: "eleven"}),
+			"@@ -2,13 +2,13 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n-11\n+eleven\n 12\n 13\n 14\n",
+		},
+		"separate hunks": {
+			numbered(20, nil), numbered(20, map[int]string{3: "three", 12: "twelve"}),
+			"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+				"@@ -9,7 +9,7 @@\n 9\n 10\n 11\n-12\n+twelve\n 13\n 14\n 15\n",
+		},
+		"insert at start": {
+			numbered(5, nil), "0\n" + numbered(5, nil),
+			"@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n",
+		},
+		"delete": {
+			"a\nb\nc\n", "a\nc\n",
+			"@@ -1,3 +1,2 @@\n a\n-b\n c\n",
+		},
+		"new file": {
+			"", "x\ny\n",
+			"@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		"removed file": {
+			"x\n", "",
+			"@@ -1 +0,0 @@\n-x\n",
+		},
+		"no newline at end": {
+			"x\n", "x",
+			"@@ -1 +1 @@\n-x\n+x\n\\ No newline at end of file\n",
+		},
+	}
+	for name, test := range tests {
+		var out bytes.Buffer
+		writeUnifiedDiff(&out, diffLines(splitLines([]byte(test.a)), splitLines([]byte(test.b))))
+		if out.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", name, out.String(), test.want)
+		}
+	}
+}
+
+func TestApplyDiffSet(t *testing.T) {
+	values := Values{ModuleName: "example.com/demo", Year: 2024, Vars: map[string]string{"Team": "core"}}
+	set := map[string]string{"ModuleName": "example.com/other", "Year": "2025", "LicenseHeaders": "true", "Team": "payments"}
+	if err := applyDiffSet(&values, set); err != nil {
+		t.Fatal(err)
+	}
+	if values.ModuleName != "example.com/other" || values.Year != 2025 || !values.LicenseHeaders || values.Vars["Team"] != "payments" {
+		t.Errorf("applyDiffSet = %+v", values)
+	}
+
+	for _, set := range []map[string]string{{"Components": "grpc"}, {"Year": "next"}} {
+		if err := applyDiffSet(&Values{Vars: map[string]string{}}, set); err == nil {
+			t.Errorf("applyDiffSet(%v) is no error", set)
+		}
+	}
+}
+
+func TestDiffExitCodes(t *testing.T) {
+	useBuiltinTemplates(t, fstest.MapFS{
+		"template/demo/README.md.tmpl": mapFile("# {{.RepoName}}\n"),
+		"template/demo/main.txt":       mapFile("a\nb\n"),
+	})
+	root := t.TempDir()
+	files := map[string]string{"README.md": "# demo\n", "main.txt": "a\nb\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := &projectLock{
+		Template: lockTemplate{Ref: "demo", Name: "demo", Source: templateSource{Kind: sourceKindBuiltin, Location: "demo"}},
+		Values:   Values{RepoName: "demo", GoVersion: minGoVersion},
+	}
+	if err := lock.write(root); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := func(dir string, set map[string]string, args ...string) (int, string) {
+		t.Helper()
+		diffSetVars = set
+		defer func() { diffSetVars = nil }()
+		var out bytes.Buffer
+		var code int
+		if err := inDir(dir, func() error {
+			code = diffWorkingProject(&out, io.Discard, args)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return code, out.String()
+	}
+
+	if code, out := diff(root, nil); code != 0 || out != "" {
+		t.Errorf("unchanged project: exit %d, output %q", code, out)
+	}
+	if code, _ := diff(t.TempDir(), nil); code != diffExitError {
+		t.Errorf("outside of a project: exit %d, want %d", code, diffExitError)
+	}
+	if code, _ := diff(root, nil, ".."); code != diffExitError {
+		t.Errorf("path outside of the project: exit %d, want %d", code, diffExitError)
+	}
+	if code, _ := diff(root, map[string]string{"Components": "grpc"}); code != diffExitError {
+		t.Errorf("--set Components: exit %d, want %d", code, diffExitError)
+	}
+
+	want := "--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-# other\n+# demo\n"
+	if code, out := diff(root, map[string]string{"RepoName": "other"}); code != diffExitDifferent || out != want {
+		t.Errorf("--set RepoName: exit %d, output %q, want %q", code, out, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.txt"), []byte("a\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want = "--- a/main.txt\n+++ b/main.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"
+	if code, out := diff(root, nil); code != diffExitDifferent || out != want {
+		t.Errorf("changed project: exit %d, output %q, want %q", code, out, want)
+	}
+	if code, out := diff(root, nil, "README.md"); code != 0 || out != "" {
+		t.Errorf("unchanged path: exit %d, output %q", code, out)
+	}
+}
+
+func TestDiffTidyWarning(t *testing.T) {
+	goMod := "module example.com/demo\n\ngo 1.18\n\nrequire example.com/missing v1.0.0\n"
+	useBuiltinTemplates(t, fstest.MapFS{
+		"template/demo/go.mod":  mapFile(goMod),
+		"template/demo/main.go": mapFile("package main\n\nimport _ \"example.com/missing\"\n\nfunc main() {}\n"),
+	})
+	root := t.TempDir()
+	files := map[string]string{"go.mod": goMod, "main.go": "package main\n\nimport _ \"example.com/missing\"\n\nfunc main() {}\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := &projectLock{
+		Template: lockTemplate{Ref: "demo", Name: "demo", Source: templateSource{Kind: sourceKindBuiltin, Location: "demo"}},
+		Values:   Values{RepoName: "demo", GoVersion: minGoVersion},
+	}
+	if err := lock.write(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failing tidy is a one line warning, out of the patch
+	var out, errOut bytes.Buffer
+	var code int
+	if err := inDir(root, func() error {
+		code = diffWorkingProject(&out, &errOut, nil)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 || out.String() != "" {
+		t.Errorf("exit %d, output %q", code, out.String())
+	}
+	warning := errOut.String()
+	want := "⚠️  go mod tidy: example.com/demo imports example.com/missing: module lookup disabled by GOPROXY=off, go.mod is compared as rendered\n"
+	if warning != want {
+		t.Errorf("warning %q, want %q", warning, want)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// gitAttributesPartial is the .gitattributes written by --git to projects
+// whose template has none.
+const gitAttributesPartial = "gitattributes"
+
+var (
+	gitInit    bool
+	gitBranch  string
+	gitRemote  string
+	gitAuthor  string
+	gitMessage string
+	gitPush    bool
+)
+
+// gitOptions is the repository created by create --git.
+type gitOptions struct {
+	Branch  string
+	Remote  string
+	Message string
+	Push    bool
+	// Author is the author and committer of the initial commit, the git
+	// configuration of the user when nil.
+	Author *mail.Address
+}
+
+// gitOptionsFromFlags returns the options of --git and the flags it
+// implies, nil when git is not asked for. The branch and the author default
+// to those of the user configuration.
+func gitOptionsFromFlags(cfg *userConfig) (*gitOptions, error) {
+	if !gitInit && gitRemote == "" && !gitPush {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("--git needs git: %w", err)
+	}
+	if gitPush && gitRemote == "" {
+		return nil, fmt.Errorf("--git-push needs --git-remote")
+	}
+	branch := firstNonEmpty(gitBranch, cfg.GitBranch, "main")
+	if err := gitCommand("", "check-ref-format", "--branch", branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name %q", branch)
+	}
+	options := &gitOptions{Branch: branch, Remote: gitRemote, Message: gitMessage, Push: gitPush}
+	if author := firstNonEmpty(gitAuthor, cfg.Author); author != "" {
+		address, err := mail.ParseAddress(author)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git author %q, expected \"Name <email>\": %w", author, err)
+		}
+		options.Author = address
+	}
+	return options, nil
+}
+
+// initGit makes the project a repository: it sets the default branch and
+// adds a .gitattributes and the origin remote; commitGit then makes the
+// initial commit. Projects created inside a work tree, e.g. in a monorepo,
+// belong to it and are left alone: initGit returns false.
+func initGit(outputDir string, options *gitOptions, values Values, p *partials) (bool, error) {
+	if topLevel, ok := gitWorkTree(outputDir); ok {
+		fmt.Printf("ℹ️  Skipping git init, %s is inside the git work tree %s\n", outputDir, topLevel)
+		return false, nil
+	}
+
+	fmt.Printf("Initializing git repository (branch %s)\n", options.Branch)
+	if err := gitCommand(outputDir, "init", "--quiet"); err != nil {
+		return false, err
+	}
+	if err := gitCommand(outputDir, "symbolic-ref", "HEAD", "refs/heads/"+options.Branch); err != nil {
+		return false, err
+	}
+
+	attributesPath := filepath.Join(outputDir, ".gitattributes")
+	if !fileExists(attributesPath) && p.set.Lookup(gitAttributesPartial) != nil {
+		if err := writePartial(attributesPath, gitAttributesPartial, values, p.set); err != nil {
+			return false, err
+		}
+	}
+
+	if options.Remote != "" {
+		if err := gitCommand(outputDir, "remote", "add", "origin", options.Remote); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// commitGit makes the initial commit of the project, then pushes it.
+func commitGit(outputDir string, options *gitOptions) error {
+	var env []string
+	if options.Author != nil {
+		env = []string{
+			"GIT_AUTHOR_NAME=" + options.Author.Name, "GIT_AUTHOR_EMAIL=" + options.Author.Address,
+			"GIT_COMMITTER_NAME=" + options.Author.Name, "GIT_COMMITTER_EMAIL=" + options.Author.Address,
+		}
+	}
+	if err := gitCommand(outputDir, "add", "--all"); err != nil {
+		return err
+	}
+	if err := gitCommandEnv(outputDir, env, "commit", "--quiet", "--message", options.Message); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Created the initial commit: %s\n", options.Message)
+
+	if options.Push {
+		fmt.Printf("⚙️  Pushing %s to %s\n", options.Branch, options.Remote)
+		if err := gitCommand(outputDir, "push", "--quiet", "--set-upstream", "origin", options.Branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitWorkTree returns the top level of the work tree dir is in.
+func gitWorkTree(dir string) (string, bool) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+// writePartial renders the named template of the partials into filePath.
+func writePartial(filePath string, name string, values Values, partials *template.Template) error {
+	set, err := partials.Clone()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := set.ExecuteTemplate(&buf, name, values); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0644)
+}
+
+func gitCommand(dir string, args ...string) error {
+	return gitCommandEnv(dir, nil, args...)
+}
+
+// gitCommandEnv runs git with env added to the environment.
+func gitCommandEnv(dir string, env []string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// licensePartialsDir holds the license texts, one .tmpl file per SPDX
+// identifier, with the shared texts in text/ and the source headers in
+// header/ (header/default.tmpl unless the license has its own).
+const licensePartialsDir = "license"
+
+var (
+	licenseID      string
+	licenseHolder  string
+	licenseHeaders bool
+	licenseYear    int
+)
+
+// licenseIDs lists the licenses available in the partials.
+func (p *partials) licenseIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, source := range p.sources {
+		entries, err := fs.ReadDir(source.FS, path.Join(source.Root, licensePartialsDir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			id := strings.TrimSuffix(entry.Name(), ".tmpl")
+			if entry.IsDir() || id == entry.Name() || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// licenseID returns the identifier of the license named id, compared
+// without case so that --license mit selects MIT.
+func (p *partials) licenseID(id string) (string, error) {
+	for _, known := range p.licenseIDs() {
+		if strings.EqualFold(known, id) {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unknown license %q, expected one of: %s", id, strings.Join(p.licenseIDs(), ", "))
+}
+
+// license renders the LICENSE file and the source header of values.License.
+// The header has no comment markers.
+func (p *partials) license(values Values) (text string, header string, err error) {
+	set, err := p.set.Clone()
+	if err != nil {
+		return "", "", err
+	}
+	var buf bytes.Buffer
+	if err := set.ExecuteTemplate(&buf, licensePartialsDir+"/"+values.License, values); err != nil {
+		return "", "", err
+	}
+	text = buf.String()
+
+	headerName := licensePartialsDir + "/header/" + values.License
+	if set.Lookup(headerName) == nil {
+		headerName = licensePartialsDir + "/header/default"
+	}
+	buf.Reset()
+	if err := set.ExecuteTemplate(&buf, headerName, values); err != nil {
+		return "", "", err
+	}
+	return text, strings.TrimSpace(buf.String()), nil
+}
+
+// applyLicense writes the LICENSE file of the project and, when
+// values.LicenseHeaders is set, adds the header to its Go files.
+func applyLicense(outputDir string, values Values, p *partials) error {
+	fmt.Printf("Writing LICENSE (%s)\n", values.License)
+	added, skipped, err := writeLicense(outputDir, values, p)
+	if err != nil {
+		return err
+	}
+	if values.LicenseHeaders {
+		fmt.Printf("Added the license header to %d Go files (%d already had one)\n", added, skipped)
+	}
+	return nil
+}
+
+// writeLicense is applyLicense without the progress messages.
+func writeLicense(outputDir string, values Values, p *partials) (added int, skipped int, err error) {
+	text, header, err := p.license(values)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "LICENSE"), []byte(text), 0644); err != nil {
+		return 0, 0, err
+	}
+	if !values.LicenseHeaders {
+		return 0, 0, nil
+	}
+	return addLicenseHeaders(outputDir, header)
+}
+
+// addLicenseHeaders prepends header, as line comments, to the Go files of
+// dir which have none yet: files whose leading comments mention a copyright
+// or an SPDX identifier are left alone, so running it twice changes nothing.
+// Directories the go tool ignores are skipped, as is vendor.
+func addLicenseHeaders(dir string, header string) (added int, skipped int, err error) {
+	var comment bytes.Buffer
+	for _, line := range strings.Split(header, "\n") {
+		comment.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+	comment.WriteString("\n")
+
+	err = filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if filePath != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(filePath) != ".go" {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if hasLicenseHeader(content) {
+			skipped++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		added++
+		return os.WriteFile(filePath, append(comment.Bytes(), content...), info.Mode().Perm())
+	})
+	return added, skipped, err
+}
+
+// hasLicenseHeader reports whether the comments before the first line of
+// code mention a copyright or an SPDX license identifier.
+func hasLicenseHeader(content []byte) bool {
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(line, "*/")
+		case line == "" || strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "/*"):
+			inBlock = !strings.Contains(line, "*/")
+		default:
+			return false
+		}
+		if strings.Contains(line, "Copyright") || strings.Contains(line, "SPDX-License-Identifier") {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultLicenseHolder is the holder of projects created without
+// --license-holder.
+func defaultLicenseHolder(repoName string) string {
+	return fmt.Sprintf("The %s Authors", repoName)
+}
+
+func completeLicenses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	p, err := loadPartials(nil)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return p.licenseIDs(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func runLicenseList(cmd *cobra.Command, args []string) {
+	p, err := loadPartials(nil)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Available licenses:")
+	for _, id := range p.licenseIDs() {
+		fmt.Printf("  - %s\n", id)
+	}
+}
+
+func runLicenseApply(cmd *cobra.Command, args []string) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	check(err)
+	if !isDir(dir) {
+		fmt.Printf("❌ Directory %s not found\n", dir)
+		os.Exit(1)
+	}
+
+	p, err := loadPartials(nil)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	id, err := p.licenseID(licenseID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	values := Values{
+		RepoName:       filepath.Base(dir),
+		License:        id,
+		LicenseHolder:  licenseHolder,
+		LicenseHeaders: true,
+		Year:           licenseYear,
+	}
+	if values.LicenseHolder == "" {
+		values.LicenseHolder = defaultLicenseHolder(values.RepoName)
+	}
+	if values.Year == 0 {
+		values.Year = time.Now().Year()
+	}
+
+	text, header, err := p.license(values)
+	if err != nil {
+		fmt.Printf("❌ Error rendering license: %v\n", err)
+		os.Exit(1)
+	}
+	if licensePath := filepath.Join(dir, "LICENSE"); !fileExists(licensePath) {
+		fmt.Printf("Writing LICENSE (%s)\n", id)
+		check(os.WriteFile(licensePath, []byte(text), 0644))
+	}
+	added, skipped, err := addLicenseHeaders(dir, header)
+	if err != nil {
+		fmt.Printf("❌ Error adding license headers: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Added the license header to %d Go files (%d already had one)\n", added, skipped)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintTest       bool
+	lintComponents []string
+)
+
+func runLint(cmd *cobra.Command, args []string) {
+	refs := args
+	if len(refs) == 0 {
+		refs = builtinTemplates()
+	}
+
+	failed := 0
+	for _, ref := range refs {
+		fmt.Printf("🔍 Linting %s\n", ref)
+		problems := lintTemplate(ref)
+		if len(problems) == 0 {
+			fmt.Printf("✅ %s: no problems found\n", ref)
+			continue
+		}
+		failed++
+		fmt.Printf("❌ %s: %d problem(s)\n", ref, len(problems))
+		for _, problem := range problems {
+			fmt.Printf("  - %s\n", strings.ReplaceAll(problem, "\n", "\n    "))
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// lintTemplate checks the template ref and the templates it extends: their
+// manifests, the syntax of every template, a render of the merged tree with
+// sample values and, with --test, the build and tests of that render.
+func lintTemplate(ref string) []string {
+	t, err := resolveTemplate(ref)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	for _, level := range t.chain() {
+		problems = append(problems, lintManifest(level)...)
+		problems = append(problems, lintTemplateFiles(level)...)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	components := lintComponents
+	if len(components) == 0 {
+		components = t.components()
+	}
+	values, err := sampleValues(t, components)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	outputDir, err := os.MkdirTemp("", "beginning-lint-")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer os.RemoveAll(outputDir)
+
+	unmatched, err := t.render(outputDir, values)
+	if err != nil {
+		return []string{fmt.Sprintf("render: %v", err)}
+	}
+	for _, pattern := range unmatched {
+		problems = append(problems, fmt.Sprintf("delete %s matches no file of the parent templates", pattern))
+	}
+	if len(problems) > 0 || !lintTest {
+		return problems
+	}
+
+	if err := setupProject(outputDir, nil); err != nil {
+		return []string{fmt.Sprintf("setup of the rendered project: %v", err)}
+	}
+	err = inDir(outputDir, func() error {
+		return runCommand("go build ./... && go vet ./... && go test ./...")
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("build and tests of the rendered project: %v", err)}
+	}
+	return nil
+}
+
+// sampleValues returns the values lint and show render t with: an example
+// project with the components and the defaults of the variables, required
+// ones set to "example".
+func sampleValues(t *projectTemplate, components []string) (Values, error) {
+	values := Values{
+		ModuleName: "example.com/example",
+		RepoName:   "example",
+		GoVersion:  t.goVersion(),
+		Components: components,
+		Vars:       map[string]string{},
+		Year:       time.Now().Year(),
+	}
+	for _, component := range components {
+		if !t.hasComponent(component) {
+			return values, fmt.Errorf("unknown component %s, the template has: %s", component, strings.Join(t.components(), ", "))
+		}
+	}
+	for _, variable := range t.variables() {
+		values.Vars[variable.Name] = variable.Default
+		if variable.Required {
+			values.Vars[variable.Name] = "example"
+		}
+	}
+	return values, nil
+}
+
+// lintManifest checks the _template.yaml of one template of a chain.
+func lintManifest(t *projectTemplate) []string {
+	var problems []string
+	if len(t.Manifest.Delete) > 0 && t.Manifest.Extends == "" {
+		problems = append(problems, fmt.Sprintf("%s: delete needs extends, there are no parent files to delete", t.ID))
+	}
+
+	if t.Manifest.GoVersion != "" && !isValidGoVersion(t.Manifest.GoVersion) {
+		problems = append(problems, fmt.Sprintf("%s: goVersion %s is not a Go version of %s or later", t.ID, t.Manifest.GoVersion, minGoVersion))
+	}
+	for component := range t.Manifest.Components {
+		if !t.hasComponent(component) {
+			problems = append(problems, fmt.Sprintf("%s: components describes %s, which is not in _components", t.ID, component))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, variable := range t.Manifest.Variables {
+		switch {
+		case !reVariableName.MatchString(variable.Name):
+			problems = append(problems, fmt.Sprintf("%s: variable name %q is not an identifier usable as {{.Vars.Name}}", t.ID, variable.Name))
+		case seen[variable.Name]:
+			problems = append(problems, fmt.Sprintf("%s: variable %s is declared twice", t.ID, variable.Name))
+		case variable.Required && variable.Default != "":
+			problems = append(problems, fmt.Sprintf("%s: variable %s is required and has a default", t.ID, variable.Name))
+		}
+		seen[variable.Name] = true
+	}
+
+	for _, hook := range t.Manifest.Hooks.Post {
+		if strings.TrimSpace(hook) == "" {
+			problems = append(problems, fmt.Sprintf("%s: empty post hook", t.ID))
+			continue
+		}
+		if _, err := template.New("hook").Funcs(templateFuncs).Parse(hook); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: post hook %q: %v", t.ID, hook, err))
+		}
+	}
+	return problems
+}
+
+// lintTemplateFiles parses the names and contents of the files rendered from
+// one template of a chain, its components included.
+func lintTemplateFiles(t *projectTemplate) []string {
+	roots := []string{t.Root}
+	if entries, err := fs.ReadDir(t.FS, path.Join(t.Root, "_components")); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				roots = append(roots, path.Join(t.Root, "_components", entry.Name()))
+			}
+		}
+	}
+
+	var problems []string
+	for _, root := range roots {
+		err := fs.WalkDir(t.FS, root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if filePath != root && strings.HasPrefix(d.Name(), "_") && !strings.HasPrefix(d.Name(), "__") {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if _, err := template.New(filePath).Funcs(templateFuncs).Parse(d.Name()); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: file name: %v", displayPath(t, filePath), err))
+			}
+			left, right := "{{", "}}"
+			switch path.Ext(filePath) {
+			case ".tmpl":
+			case ".btmpl":
+				left, right = "[[", "]]"
+			default:
+				return nil
+			}
+			content, err := fs.ReadFile(t.FS, filePath)
+			if err != nil {
+				return err
+			}
+			if _, err := template.New(d.Name()).Delims(left, right).Funcs(templateFuncs).Parse(string(content)); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", displayPath(t, filePath), err))
+			}
+			return nil
+		})
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// displayPath names a file of a template the way its author knows it.
+func displayPath(t *projectTemplate, filePath string) string {
+	if t.Dir == "" {
+		return filePath
+	}
+	return filepath.Join(t.Dir, filepath.FromSlash(filePath))
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// lockFileName is the file create writes at the root of a project to record
+// how it was generated.
+const lockFileName = ".beginning.yaml"
+
+var statusAll bool
+
+// projectLock records how a project was generated: the version of
+// beginning, the template, the values and a hash of every generated file.
+type projectLock struct {
+	Beginning string       `yaml:"beginning"`
+	Template  lockTemplate `yaml:"template"`
+	Values    Values       `yaml:"values"`
+	// Files maps the slash separated path of each generated file to the
+	// sha256 of its content.
+	Files map[string]string `yaml:"files"`
+}
+
+// lockTemplate is the template a project was generated from.
+type lockTemplate struct {
+	// Ref is the template as given to create --type.
+	Ref     string `yaml:"ref"`
+	Name    string `yaml:"name"`
+	Version string `yaml:"version,omitempty"`
+	// Source is where the template was found, a built-in, a directory, a
+	// git repository or a module, with Path the directory of the template in
+	// it.
+	Source templateSource `yaml:"source"`
+	Path   string         `yaml:"path,omitempty"`
+	// Revision is the commit of templates in git and the version of those
+	// in modules. Built-in templates go with the version of beginning.
+	Revision string `yaml:"revision,omitempty"`
+}
+
+// newProjectLock records the files of the project in dir, generated by t
+// from values.
+func newProjectLock(dir string, ref string, t *projectTemplate, values Values) (*projectLock, error) {
+	lock := &projectLock{
+		Beginning: beginningVersion(),
+		Template: lockTemplate{
+			Ref:      ref,
+			Name:     t.name(),
+			Version:  t.Manifest.Version,
+			Source:   t.Source,
+			Path:     t.Path,
+			Revision: t.revision(),
+		},
+		Values: values,
+		Files:  map[string]string{},
+	}
+	files, err := projectFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		sum, err := fileHash(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		lock.Files[name] = sum
+	}
+	return lock, nil
+}
+
+// write saves the lock at the root of the project in dir.
+func (l *projectLock) write(dir string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Written by beginning create, see 'beginning status'. Do not edit.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, lockFileName), buf.Bytes(), 0644)
+}
+
+// findProjectLock reads the lock of the project dir belongs to, looking in
+// the parents of dir as the go tool does for go.mod.
+func findProjectLock(dir string) (string, *projectLock, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	for start := dir; ; {
+		content, err := os.ReadFile(filepath.Join(dir, lockFileName))
+		if err == nil {
+			lock := &projectLock{}
+			if err := yaml.Unmarshal(content, lock); err != nil {
+				return "", nil, fmt.Errorf("%s: %w", filepath.Join(dir, lockFileName), err)
+			}
+			return dir, lock, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, fmt.Errorf("no %s in %s or its parents, it was not generated by beginning", lockFileName, start)
+		}
+		dir = parent
+	}
+}
+
+// revision returns the commit of templates in a git repository, with a
+// -dirty suffix when they have uncommitted changes, and the version of
+// those in the module cache.
+func (t *projectTemplate) revision() string {
+	if t.Dir == "" {
+		return ""
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(t.Dir), "/") {
+		if i := strings.LastIndex(elem, "@v"); i > 0 {
+			return elem[i+1:]
+		}
+	}
+	head, err := gitOutput(t.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	if changes, err := gitOutput(t.Dir, "status", "--porcelain", "--", "."); err == nil && changes != "" {
+		head += "-dirty"
+	}
+	return head
+}
+
+// beginningVersion returns the module version of the binary, (devel) when
+// it was built from a checkout.
+func beginningVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// projectFiles lists the files of the project in dir as slash separated
+// paths, leaving out the lock file, .git and the projects created inside it,
+// such as the modules of a workspace, which have a lock of their own.
+func projectFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if filePath != dir && (d.Name() == ".git" || fileExists(filepath.Join(filePath, lockFileName))) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); rel != lockFileName {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// gitIgnored reports which of files, relative to dir, git ignores. Outside
+// of a work tree it ignores none.
+func gitIgnored(dir string, files []string) map[string]bool {
+	ignored := map[string]bool{}
+	if _, ok := gitWorkTree(dir); !ok || len(files) == 0 {
+		return ignored
+	}
+	cmd := exec.Command("git", "check-ignore", "--no-index", "--stdin", "-z")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\x00") + "\x00")
+	// check-ignore exits with 1 when no file is ignored
+	output, _ := cmd.Output()
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			ignored[name] = true
+		}
+	}
+	return ignored
+}
+
+// gitOutput runs git in dir and returns its trimmed standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func fileHash(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// projectStatus sorts the files of a project by how they compare to the
+// lock.
+type projectStatus struct {
+	Unchanged    []string
+	Modified     []string
+	Deleted      []string
+	NotGenerated []string
+}
+
+func newProjectStatus(dir string, lock *projectLock) (*projectStatus, error) {
+	status := &projectStatus{}
+	for _, name := range sortedKeys(lock.Files) {
+		sum, err := fileHash(filepath.Join(dir, filepath.FromSlash(name)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			status.Deleted = append(status.Deleted, name)
+		case err != nil:
+			return nil, err
+		case sum != lock.Files[name]:
+			status.Modified = append(status.Modified, name)
+		default:
+			status.Unchanged = append(status.Unchanged, name)
+		}
+	}
+
+	files, err := projectFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for _, name := range files {
+		if _, ok := lock.Files[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	ignored := gitIgnored(dir, added)
+	for _, name := range added {
+		if !ignored[name] {
+			status.NotGenerated = append(status.NotGenerated, name)
+		}
+	}
+	sort.Strings(status.NotGenerated)
+	return status, nil
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	root, lock, err := findProjectLock(dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	status, err := newProjectStatus(root, lock)
+	if err != nil {
+		fmt.Printf("❌ Error reading project files: %v\n", err)
+		os.Exit(1)
+	}
+
+	from := lock.Template.Ref
+	if lock.Template.Version != "" {
+		from += " " + lock.Template.Version
+	}
+	if lock.Template.Revision != "" {
+		from += " (" + lock.Template.Revision + ")"
+	}
+	fmt.Printf("Project %s (%s) in %s\n", lock.Values.RepoName, lock.Values.ModuleName, root)
+	fmt.Printf("Generated by beginning %s from template %s\n", lock.Beginning, from)
+	if len(lock.Values.Components) > 0 {
+		fmt.Printf("Components: %s\n", strings.Join(lock.Values.Components, ", "))
+	}
+
+	printStatusFiles := func(title string, mark string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, name := range files {
+			fmt.Printf("  %s %s\n", mark, name)
+		}
+	}
+	if statusAll {
+		printStatusFiles("Unchanged", " ", status.Unchanged)
+	}
+	printStatusFiles("Modified", "M", status.Modified)
+	printStatusFiles("Deleted", "D", status.Deleted)
+	printStatusFiles("Not generated", "?", status.NotGenerated)
+
+	fmt.Printf("\n%d unchanged, %d modified, %d deleted, %d not generated\n",
+		len(status.Unchanged), len(status.Modified), len(status.Deleted), len(status.NotGenerated))
+}
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -23,6 +24,13 @@ var (
 	reMultiUnder = regexp.MustCompile(`_+`)            // multiple underscores
 )
 
+// templateFuncs are the functions available to templates, file names and
+// hooks
+var templateFuncs = template.FuncMap{
+	"sanitize": sanitize,
+	"upper":    strings.ToUpper,
+}
+
 // sanitize converts special chars to "", collapses repeats, trims edges
 func sanitize(s string) string {
 	// note: skip lowercasing if you want case preserved
@@ -33,14 +41,39 @@ func sanitize(s string) string {
 }
 
 type Values struct {
-	ModuleName string `yaml:"ModuleName"`
-	RepoName   string `yaml:"RepoName"`
-	GoVersion  string `yaml:"GoVersion"`
+	ModuleName string   `yaml:"ModuleName"`
+	RepoName   string   `yaml:"RepoName"`
+	GoVersion  string   `yaml:"GoVersion"`
+	Components []string `yaml:"Components"`
+	// Vars holds the variables declared by the _template.yaml of the
+	// template, e.g. {{.Vars.Team}}
+	Vars map[string]string `yaml:"Vars"`
+	// License is the SPDX identifier of the LICENSE file, none when empty
+	License        string `yaml:"License"`
+	LicenseHolder  string `yaml:"LicenseHolder"`
+	LicenseHeaders bool   `yaml:"LicenseHeaders"`
+	// Year is the copyright year, the current one by default
+	Year int `yaml:"Year"`
+}
+
+// Has reports whether the optional component is enabled, e.g. {{if .Has "grpc"}}
+func (v Values) Has(component string) bool {
+	for _, c := range v.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
 }
 
-//go:embed template
+// all: keeps the underscore-prefixed entries (optional components) in the binary
+//
+//go:embed all:template
 var templateFS embed.FS
 
+// minGoVersion is the oldest Go version of generated projects.
+const minGoVersion = "1.24"
+
 var (
 	valuesFile   string
 	moduleName   string
@@ -48,6 +81,8 @@ var (
 	goVersion    string
 	outputDir    string
 	templateType string
+	components   []string
+	setVars      map[string]string
 )
 
 func main() {
@@ -61,7 +96,7 @@ The tool automatically generates project structure, dependencies, and configurat
 based on best practices and your specific requirements.
 
 Features:
-• Multiple template types (service, library, etc.)
+• Multiple template types (service, cli, library, workspace, etc.)
 • Flexible output directory configuration
 • Template customization via values.yaml or CLI flags
 • Automatic dependency management
@@ -71,6 +106,7 @@ Features:
 Examples:
   beginning list                           # Show available template types
   beginning create -t service -r myapi    # Create a service project
+  beginning create -t cli -r mytool      # Create a command-line tool
   beginning create -t library -r mylib    # Create a library project
   beginning create --help                 # Show detailed help`,
 	}
@@ -87,44 +123,77 @@ This command will:
 4. Run post-generation setup scripts (if available)
 5. Initialize Go modules and dependencies
 
+Inside a go.work workspace, the project becomes one of its modules: the
+module name defaults to the workspace module path followed by the project
+directory, the module is added with 'go work use', and the .gitignore
+patterns and bin/ scripts the workspace already provides are left out.
+
 Template Types:
 • service: Full-featured microservice with API, database, swagger docs
-• library: Simple Go library with basic structure
+• cli: Command-line tool with cobra, XDG config, json/table output and release builds
+• library: Go library with options, sentinel errors, examples, fuzz test and benchmark
+• workspace: Monorepo with go.work, a shared pkg/ module and a root test script
 • (more types can be added to template/ directory)
 
+Optional Components (--with):
+• grpc (service): gRPC server next to HTTP with health, reflection and an example proto
+• auth (service): JWT authentication with role and scope guards
+• ratelimit (service): token-bucket rate limiting with in-process or Redis stores
+• worker (service): background job worker with a queue, retries and dead-lettering
+• cache (service): typed Cache[K,V] on an in-process LRU or Redis with singleflight loading
+• deploy (service): Helm chart with probes, HPA, PodDisruptionBudget and a config.yaml ConfigMap
+
 Examples:
   beginning create -t service -r myapi -m github.com/company/myapi
+  beginning create -t service -r myapi -m github.com/company/myapi --with grpc,worker
+  beginning create -t cli -r mytool -m github.com/company/mytool
   beginning create -t library -r myutils -o /path/to/output
+  beginning create -t workspace -r backend -m github.com/company/backend
+  cd backend/services && beginning create -t service -r api   # github.com/company/backend/services/api
   beginning create -v custom-values.yaml`,
 		Run: runScaffold,
 	}
 
 	scaffoldCmd.Flags().StringVarP(&valuesFile, "values", "v", "values.yaml", "Path to values.yaml configuration file (optional if using CLI flags)")
-	scaffoldCmd.Flags().StringVarP(&moduleName, "module", "m", "", "Go module name (e.g., github.com/company/project)")
+	scaffoldCmd.Flags().StringVarP(&moduleName, "module", "m", "", "Go module name (e.g., github.com/company/project), derived from the workspace inside a go.work")
 	scaffoldCmd.Flags().StringVarP(&repoName, "repo", "r", "", "Repository/project name (used for directory naming)")
-	scaffoldCmd.Flags().StringVarP(&goVersion, "go-version", "g", "1.24", "Go version to use (defaults to 1.24 if not specified)")
+	scaffoldCmd.Flags().StringVarP(&goVersion, "go-version", "g", "", "Go version to use (defaults to the user configuration, then 1.24)")
 	scaffoldCmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory path (defaults to ./{repo-name})")
-	scaffoldCmd.Flags().StringVarP(&templateType, "type", "t", "service", "Template type to use (service, library, etc.)")
+	scaffoldCmd.Flags().StringVarP(&templateType, "type", "t", "", "Template to use: a built-in type (service, cli, library, workspace), an alias or a template of a source, a template directory or a git repository (defaults to the user configuration, then service)")
+	scaffoldCmd.Flags().StringSliceVarP(&components, "with", "w", nil, "Optional components to include (e.g. grpc), see 'beginning list'")
+	scaffoldCmd.Flags().StringToStringVar(&setVars, "set", nil, "Variables declared by the template's _template.yaml (e.g. --set Team=platform)")
+	scaffoldCmd.Flags().StringVar(&licenseID, "license", "", "SPDX identifier of the LICENSE to write (e.g. MIT, Apache-2.0, Proprietary), see 'beginning license list'")
+	scaffoldCmd.Flags().StringVar(&licenseHolder, "license-holder", "", "Copyright holder of the license (defaults to \"The <repo-name> Authors\")")
+	scaffoldCmd.Flags().BoolVar(&licenseHeaders, "license-headers", false, "Add the license header to every generated .go file")
+	scaffoldCmd.Flags().BoolVar(&gitInit, "git", false, "Initialize a git repository with an initial commit (skipped inside a git work tree)")
+	scaffoldCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Default branch of the git repository (defaults to the user configuration, then main)")
+	scaffoldCmd.Flags().StringVar(&gitRemote, "git-remote", "", "URL of the origin remote (implies --git)")
+	scaffoldCmd.Flags().StringVar(&gitAuthor, "git-author", "", "Author of the initial commit, \"Name <email>\" (defaults to the user configuration, then the git one)")
+	scaffoldCmd.Flags().StringVar(&gitMessage, "git-message", "Initial commit", "Message of the initial commit")
+	scaffoldCmd.Flags().BoolVar(&gitPush, "git-push", false, "Push the initial commit to the origin remote (implies --git)")
 
 	// Add completion for template types
 	scaffoldCmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		var templates []string
-		fs.WalkDir(templateFS, "template", func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if path == "template" {
-				return nil
-			}
-			parts := strings.Split(path, "/")
-			if len(parts) == 2 && d.IsDir() {
-				templates = append(templates, parts[1])
-			}
-			return nil
-		})
-		return templates, cobra.ShellCompDirectiveNoFileComp
+		completions, directive := completeTemplates(cmd, args, toComplete)
+		return append(completions, registryCompletions()...), directive
+	})
+
+	// Add completion for optional components of the selected template type
+	scaffoldCmd.RegisterFlagCompletionFunc("with", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		t, err := resolveTemplate(cfg.templateType(templateType))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return t.components(), cobra.ShellCompDirectiveNoFileComp
 	})
 
+	// Add completion for licenses
+	scaffoldCmd.RegisterFlagCompletionFunc("license", completeLicenses)
+
 	// Add completion for go-version flag
 	scaffoldCmd.RegisterFlagCompletionFunc("go-version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		versions := []string{"1.24", "1.25", "1.26", "1.27", "1.28", "1.29", "1.30"}
@@ -133,23 +202,356 @@ Examples:
 
 	rootCmd.AddCommand(scaffoldCmd)
 
+	// Add add command to extend a generated project
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add code to a generated project",
+		Long: `Add code to a project generated by beginning.
+
+Examples:
+  beginning add api --spec api/openapi.yaml    # Generate endpoints from an OpenAPI spec
+  beginning add component cache                # Add an optional component to a service`,
+	}
+
+	var addAPICmd = &cobra.Command{
+		Use:   "api",
+		Short: "Generate HTTP endpoints of a service from an OpenAPI spec",
+		Long: `Generate the HTTP endpoints of a service from an OpenAPI 3 spec (YAML or JSON).
+
+This command will:
+1. Generate the request and response types in the schema package
+2. Generate a controller interface per tag and gin routers that bind and
+   validate the path, query, header and JSON body of each operation
+3. Create a stub controller per tag, answering 501 until implemented
+4. Run bin/wire.sh to inject the new controllers and routers
+
+Generated files end in .gen.go and are rewritten on every run. Controllers are
+never rewritten: the methods of operations added to the spec later are written
+to <tag>_controller_pending.go. The spec path is recorded, so running the
+command again without --spec regenerates the API.
+
+A server URL ending in a version (/api/v1) mounts the routers under
+server.apiPrefix and that version.
+
+Examples:
+  beginning add api --spec api/openapi.yaml
+  beginning add api --regenerate               # Only rewrite the .gen.go files
+  beginning add api --spec openapi.yaml --dir ./myapi`,
+		Run: runAddAPI,
+	}
+	addAPICmd.Flags().StringVarP(&specFile, "spec", "s", "", "Path to the OpenAPI spec (defaults to the one of the previous run)")
+	addAPICmd.Flags().StringVarP(&projectDir, "dir", "d", ".", "Directory of the generated service")
+	addAPICmd.Flags().BoolVar(&regenerate, "regenerate", false, "Only rewrite the generated files, never create or extend controllers")
+	addAPICmd.MarkFlagFilename("spec", "yaml", "yml", "json")
+	addAPICmd.MarkFlagDirname("dir")
+	addCmd.AddCommand(addAPICmd)
+
+	var addComponentCmd = &cobra.Command{
+		Use:   "component <name>",
+		Short: "Add an optional component to a generated project",
+		Long: `Add one of the optional components of the template, those create takes with
+--with/-w, to a project it generated, as recorded in .beginning.yaml.
+
+This command will:
+1. Render the template, at the revision the project was generated from, with
+   and without the component
+2. Create the files of the component
+3. Update the files it changes which the project left as generated, and merge
+   its changes into the edited ones: fields of config.App, providers of the
+   wire.Build call of cmd/wire.go and the routers of the service are inserted
+   next to their neighbours in the syntax tree, as are the sections of
+   config/config.yaml and compose.yaml
+4. Run the swagger, go mod tidy and wire steps of create again, then the
+   post hooks of the template
+5. Record the component and the new files in .beginning.yaml
+
+Nothing is written when the project diverged too far from the template to
+merge safely, such as a changed call both edited and extended by the
+component: the conflicts are listed instead. Other edited files, the README
+for one, are left for the developer with a warning.
+
+Examples:
+  beginning add component cache
+  beginning add component auth --dry-run       # Show the files it would write
+  beginning add component grpc --dir ./myapi`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeComponentsToAdd,
+		Run:               runAddComponent,
+	}
+	addComponentCmd.Flags().StringVarP(&projectDir, "dir", "d", ".", "Directory of the generated project")
+	addComponentCmd.Flags().BoolVar(&componentDryRun, "dry-run", false, "List the files it would write without writing them")
+	addComponentCmd.MarkFlagDirname("dir")
+	addCmd.AddCommand(addComponentCmd)
+	rootCmd.AddCommand(addCmd)
+
 	// Add list command to show available template types
 	var listCmd = &cobra.Command{
-		Use:   "list",
+		Use:   "list [template...]",
 		Short: "List available template types",
 		Long: `Display all available template types that can be used with the create command.
 
-This command scans the template directory and shows you what project types
-are available for scaffolding. Each template type represents a different
-project structure and configuration.
+This command shows the built-in templates with their version, minimum Go
+version, tags and description, as a table or as JSON. Given template
+directories or git repositories, it shows them instead.
 
 Examples:
   beginning list                    # Show all available templates
+  beginning list --tag http         # Show the templates tagged http
+  beginning list -o json            # Show every detail as JSON
+  beginning list ./templates/api    # Show a template of your own
   beginning list --help            # Show detailed help`,
 		Run: listTemplates,
 	}
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format: table or json")
+	listCmd.Flags().StringSliceVar(&listTags, "tag", nil, "Only show the templates with these tags")
+	listCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	rootCmd.AddCommand(listCmd)
 
+	// Add show command to describe a template
+	var showCmd = &cobra.Command{
+		Use:   "show <template>",
+		Short: "Show the details and files of a template",
+		Long: `Show a template: its description, metadata, variables with their defaults,
+optional components, hooks and the files of a project rendered from it with
+sample values.
+
+Examples:
+  beginning show service
+  beginning show service --with grpc,worker    # Files with components
+  beginning show ./templates/api`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateArg,
+		Run:               runShow,
+	}
+	showCmd.Flags().StringSliceVarP(&showComponents, "with", "w", nil, "Components to render in the file tree")
+	rootCmd.AddCommand(showCmd)
+
+	// Add lint command to check templates
+	var lintCmd = &cobra.Command{
+		Use:   "lint [template...]",
+		Short: "Check templates and the templates they extend",
+		Long: `Check templates, the built-in ones by default.
+
+This command will:
+1. Resolve the templates they extend, reporting unknown parents and cycles
+2. Validate each _template.yaml (fields, variables, delete, hooks)
+3. Parse every file name and .tmpl/.btmpl file, components included
+4. Render the merged template with sample values and all components,
+   reporting delete patterns that match no file of the parents
+5. With --test, set the project up like create and run
+   go build, go vet and go test on it
+
+Examples:
+  beginning lint                                  # Lint the built-in templates
+  beginning lint ./templates/api --test           # Lint, build and test a template
+  beginning lint service --with grpc,worker --test`,
+		Run: runLint,
+	}
+	lintCmd.Flags().BoolVar(&lintTest, "test", false, "Also build, vet and test the rendered project")
+	lintCmd.Flags().StringSliceVarP(&lintComponents, "with", "w", nil, "Components to render (defaults to all of them)")
+	rootCmd.AddCommand(lintCmd)
+
+	// Add license command to license existing projects
+	var licenseCmd = &cobra.Command{
+		Use:   "license",
+		Short: "List licenses and add license headers to a project",
+		Long: `Manage the license of a project.
+
+New projects get one with 'beginning create --license <spdx-id>', which writes
+LICENSE and, with --license-headers, adds the header to every .go file.
+
+Examples:
+  beginning license list                              # Show available licenses
+  beginning license apply --license MIT              # Add headers to the current project
+  beginning license apply ./myapi --license Apache-2.0 --holder "Company Inc."`,
+	}
+	var licenseListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List available licenses",
+		Run:   runLicenseList,
+	}
+	var licenseApplyCmd = &cobra.Command{
+		Use:   "apply [dir]",
+		Short: "Add the license header to the Go files of a project",
+		Long: `Add the license header to the Go files of an existing project.
+
+Files whose leading comments already mention a copyright or an SPDX
+identifier are left alone, so the command can run again after adding code.
+LICENSE is written when the project has none.
+
+Examples:
+  beginning license apply --license MIT
+  beginning license apply ./myapi --license Proprietary --holder "Company Inc." --year 2024`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runLicenseApply,
+	}
+	licenseApplyCmd.Flags().StringVar(&licenseID, "license", "", "SPDX identifier of the license (e.g. MIT, Apache-2.0, Proprietary)")
+	licenseApplyCmd.Flags().StringVar(&licenseHolder, "holder", "", "Copyright holder (defaults to \"The <dir-name> Authors\")")
+	licenseApplyCmd.Flags().IntVar(&licenseYear, "year", 0, "Copyright year (defaults to the current year)")
+	licenseApplyCmd.MarkFlagRequired("license")
+	licenseApplyCmd.RegisterFlagCompletionFunc("license", completeLicenses)
+	licenseCmd.AddCommand(licenseListCmd, licenseApplyCmd)
+	rootCmd.AddCommand(licenseCmd)
+
+	// Add status command to compare a project with what was generated
+	var statusCmd = &cobra.Command{
+		Use:   "status [dir]",
+		Short: "Show the generated files that were modified or deleted",
+		Long: `Compare a project with what beginning generated.
+
+'beginning create' records the template, the values and a hash of every
+generated file in .beginning.yaml. Run inside the project (or give its
+directory), status lists the generated files that were modified or deleted
+since, and the files that were not generated, leaving out those git ignores.
+
+Examples:
+  beginning status
+  beginning status ./myapi --all    # Also list the unchanged files`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runStatus,
+	}
+	statusCmd.Flags().BoolVarP(&statusAll, "all", "a", false, "Also list the unchanged files")
+	rootCmd.AddCommand(statusCmd)
+
+	// Add diff command to compare a project with a fresh render
+	var diffCmd = &cobra.Command{
+		Use:   "diff [path...]",
+		Short: "Show how a project differs from what its template renders",
+		Long: `Compare a project with what its template would generate today.
+
+Run inside a project created by beginning, diff renders the template and
+values recorded in .beginning.yaml again, in a temporary directory, and prints
+a unified diff from the rendered files (a/) to those of the project (b/).
+Files written by the setup of the project or the hooks, such as go.sum, and
+files the template does not render are not compared.
+
+The exit code is 0 without differences, 1 with differences and 2 on errors.
+
+Examples:
+  beginning diff                                  # Against the latest template
+  beginning diff --template-version v1.3.0        # Against another template version
+  beginning diff --template-version <revision>    # Only the changes made to the project
+  beginning diff --set ModuleName=example.com/x   # As if generated with other values
+  beginning diff internal config/config.yaml      # Only these paths
+  beginning diff --ignore '*.md' --stat           # Changed lines per file`,
+		Run: runDiff,
+	}
+	diffCmd.Flags().StringVar(&diffTemplateVersion, "template-version", "", "Git revision or module version of the template, the beginning version for built-in ones (defaults to the latest one)")
+	diffCmd.Flags().StringToStringVar(&diffSetVars, "set", nil, "Override template variables or values recorded in .beginning.yaml, except Components (e.g. --set Team=platform, --set ModuleName=example.com/x)")
+	diffCmd.Flags().StringSliceVar(&diffIgnore, "ignore", nil, "Glob of the paths or file names to leave out (e.g. '*.md', docs)")
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Only show the number of changed lines per file")
+	rootCmd.AddCommand(diffCmd)
+
+	// Add config command to manage the defaults of the user
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Get and set the defaults of new projects",
+		Long: `Manage the user configuration, the defaults of 'beginning create'.
+
+The configuration lives in $XDG_CONFIG_HOME/beginning/config.yaml
+(~/.config/beginning/config.yaml by default, or $BEGINNING_CONFIG). Flags and
+values.yaml take precedence over it; setting a key to "" unsets it.
+
+Examples:
+  beginning config set module-prefix github.com/ourorg
+  beginning config set author "Jane Doe <jane@ourorg.com>"
+  beginning config set license Apache-2.0
+  beginning config list`,
+	}
+	var configGetCmd = &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print the value of a key",
+		Args:              cobra.ExactArgs(1),
+		Run:               runConfigGet,
+		ValidArgsFunction: completeUserConfigKeys,
+	}
+	var configSetCmd = &cobra.Command{
+		Use:               "set <key> <value>",
+		Short:             "Set the value of a key, \"\" to unset it",
+		Args:              cobra.ExactArgs(2),
+		Run:               runConfigSet,
+		ValidArgsFunction: completeUserConfigKeys,
+	}
+	var configListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the keys with their values",
+		Args:  cobra.NoArgs,
+		Run:   runConfigList,
+	}
+	var configPathCmd = &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the user configuration",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			path, err := userConfigPath()
+			check(err)
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+		},
+	}
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configPathCmd)
+	rootCmd.AddCommand(configCmd)
+
+	// Add source command to manage the template registry
+	var sourceCmd = &cobra.Command{
+		Use:   "source",
+		Short: "Manage template sources and aliases",
+		Long: `Manage the template sources of the user configuration.
+
+A source is a directory, a git repository or a Go module holding templates,
+one per subdirectory. 'beginning create -t <source>/<template>' uses the
+template of a source, and aliases name such templates.
+
+Examples:
+  beginning source add ourorg https://github.com/ourorg/templates.git#main
+  beginning source add local ~/templates
+  beginning source add mod github.com/ourorg/templates@v1.2.0
+  beginning source alias api ourorg/grpc-service
+  beginning create -t api -m github.com/ourorg/orders`,
+	}
+	var sourceAddCmd = &cobra.Command{
+		Use:   "add <name> <location>",
+		Short: "Add a template source",
+		Args:  cobra.ExactArgs(2),
+		Run:   runSourceAdd,
+	}
+	sourceAddCmd.Flags().StringVar(&sourceKind, "kind", "", "Kind of the source: dir, git or module (detected from the location by default)")
+	sourceAddCmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions([]string{sourceKindDir, sourceKindGit, sourceKindModule}, cobra.ShellCompDirectiveNoFileComp))
+	var sourceRemoveCmd = &cobra.Command{
+		Use:               "remove <name>",
+		Short:             "Remove a template source",
+		Args:              cobra.ExactArgs(1),
+		Run:               runSourceRemove,
+		ValidArgsFunction: completeSourceNames,
+	}
+	var sourceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the template sources and aliases",
+		Args:  cobra.NoArgs,
+		Run:   runSourceList,
+	}
+	var sourceRefreshCmd = &cobra.Command{
+		Use:               "refresh [name...]",
+		Short:             "Fetch the latest templates of git and module sources",
+		Run:               runSourceRefresh,
+		ValidArgsFunction: completeSourceNames,
+	}
+	var sourceAliasCmd = &cobra.Command{
+		Use:   "alias <alias> <template>",
+		Short: "Name a template, e.g. api for ourorg/grpc-service",
+		Args:  cobra.ExactArgs(2),
+		Run:   runSourceAlias,
+	}
+	var sourceUnaliasCmd = &cobra.Command{
+		Use:   "unalias <alias>",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSourceUnalias,
+	}
+	sourceCmd.AddCommand(sourceAddCmd, sourceRemoveCmd, sourceListCmd, sourceRefreshCmd, sourceAliasCmd, sourceUnaliasCmd)
+	rootCmd.AddCommand(sourceCmd)
+
 	// Add completion command
 	var completionCmd = &cobra.Command{
 		Use:   "completion",
@@ -217,46 +619,84 @@ Supported shells:
 	rootCmd.Execute()
 }
 
-func listTemplates(cmd *cobra.Command, args []string) {
-	fmt.Println("Available template types:")
+func runScaffold(cmd *cobra.Command, args []string) {
+	// Defaults of the user come from the user configuration
+	cfg := mustLoadUserConfig()
+	templateType = cfg.templateType(templateType)
 
-	err := fs.WalkDir(templateFS, "template", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	// Resolve the template and the templates it extends
+	t, err := resolveTemplate(templateType)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println("Use 'beginning list' to see available template types")
+		os.Exit(1)
+	}
 
-		if path == "template" {
-			return nil
+	values := loadValues(cfg)
+
+	// Validate optional components exist for this template type
+	for _, component := range values.Components {
+		if !t.hasComponent(component) {
+			fmt.Printf("❌ Component '%s' not found for template type '%s'!\n", component, templateType)
+			fmt.Println("Use 'beginning list' to see available components")
+			os.Exit(1)
 		}
+	}
 
-		// Get the first level directory (template type)
-		parts := strings.Split(path, "/")
-		if len(parts) == 2 && d.IsDir() {
-			fmt.Printf("  - %s\n", parts[1])
+	// Check the minimum Go version of the template, raising the default
+	if required := t.goVersion(); compareGoVersions(values.GoVersion, required) < 0 {
+		if cmd.Flags().Changed("go-version") {
+			fmt.Printf("❌ Template %s needs Go %s or later, got %s\n", templateType, required, values.GoVersion)
+			os.Exit(1)
 		}
+		values.GoVersion = required
+		fmt.Printf("ℹ️  Using Go version %s, the minimum of template %s\n", required, templateType)
+	}
 
-		return nil
-	})
+	// Fill in the variables of the template
+	if err := t.applyVariables(&values); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
 
+	// Load the partials shared by the templates, license texts included
+	partials, err := t.partials()
 	if err != nil {
-		fmt.Printf("❌ Error listing templates: %v\n", err)
+		fmt.Printf("❌ Error loading partials: %v\n", err)
 		os.Exit(1)
 	}
-}
-
-func runScaffold(cmd *cobra.Command, args []string) {
-	// Validate template type exists
-	if !templateTypeExists(templateType) {
-		fmt.Printf("❌ Template type '%s' not found!\n", templateType)
-		fmt.Println("Use 'beginning list' to see available template types")
+	if values.Year == 0 {
+		values.Year = time.Now().Year()
+	}
+	if values.License != "" {
+		id, err := partials.licenseID(values.License)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			fmt.Println("Use 'beginning license list' to see available licenses")
+			os.Exit(1)
+		}
+		values.License = id
+		if values.LicenseHolder == "" {
+			values.LicenseHolder = defaultLicenseHolder(values.RepoName)
+		}
+	} else if values.LicenseHeaders {
+		fmt.Println("❌ License headers need a license, use --license")
 		os.Exit(1)
 	}
 
-	values := loadValues()
+	// Check the git options before writing anything
+	gitOpts, err := gitOptionsFromFlags(cfg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
 
 	// Determine output directory
 	if outputDir == "" {
-		outputDir = fmt.Sprintf("./%s", values.RepoName)
+		if outputDir, err = cfg.outputDir(values.RepoName); err != nil {
+			fmt.Printf("❌ Error resolving output path: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// Convert to absolute path if relative
@@ -269,6 +709,30 @@ func runScaffold(cmd *cobra.Command, args []string) {
 		outputDir = absPath
 	}
 
+	// Inside a go.work the project becomes a module of the workspace, named
+	// after its directory unless -m is given
+	var ws *workspace
+	if !t.hasFile("go.work.tmpl") {
+		ws = findWorkspace(filepath.Dir(outputDir))
+	}
+	if values.ModuleName == "" && ws != nil {
+		modulePath, err := ws.modulePath(outputDir)
+		if err != nil {
+			fmt.Printf("❌ Module name is required, it cannot be derived from the workspace: %v\n", err)
+			os.Exit(1)
+		}
+		values.ModuleName = modulePath
+		fmt.Printf("ℹ️  Using module name %s from workspace %s\n", modulePath, ws.Dir)
+	}
+	if values.ModuleName == "" && cfg.ModulePrefix != "" {
+		values.ModuleName = cfg.ModulePrefix + "/" + values.RepoName
+		fmt.Printf("ℹ️  Using module name %s from the module prefix of the user configuration\n", values.ModuleName)
+	}
+	if values.ModuleName == "" {
+		fmt.Println("❌ Module name is required. Use -m flag, provide in values.yaml or set a prefix with 'beginning config set module-prefix'")
+		os.Exit(1)
+	}
+
 	fmt.Printf("Scaffolding %s project in: %s\n", templateType, outputDir)
 
 	// Create output directory if it doesn't exist
@@ -279,83 +743,201 @@ func runScaffold(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Use the specific template type
-	templatePath := fmt.Sprintf("template/%s", templateType)
+	// Render the template over the templates it extends, with the files of
+	// each enabled component
+	for _, component := range values.Components {
+		fmt.Printf("Adding component: %s\n", component)
+	}
+	if _, err := t.renderWith(outputDir, values, partials); err != nil {
+		fmt.Printf("❌ Error rendering template: %v\n", err)
+		os.Exit(1)
+	}
 
-	err := fs.WalkDir(templateFS, templatePath, func(path string, d fs.DirEntry, err error) error {
-		check(err)
-		if path == templatePath {
-			return nil
+	// Leave out the .gitignore patterns and scripts of the workspace
+	if ws != nil {
+		check(ws.dedupe(outputDir))
+	}
+
+	fmt.Printf("✅ %s project scaffolded: %s\n", strings.Title(templateType), outputDir)
+
+	// Set up the project, then add the license, after the generated code so
+	// it gets the headers too, then run the hooks of the template
+	check(setupProject(outputDir, ws))
+	if values.License != "" {
+		check(applyLicense(outputDir, values, partials))
+	}
+	check(inDir(outputDir, func() error {
+		return t.runPostHooks(values)
+	}))
+
+	// Make the project a repository, record how it was generated, the
+	// .gitattributes of git included, then commit it as the template left it
+	commit := false
+	if gitOpts != nil {
+		if commit, err = initGit(outputDir, gitOpts, values, partials); err != nil {
+			fmt.Printf("❌ Error setting up git: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	lock, err := newProjectLock(outputDir, templateType, t, values)
+	if err == nil {
+		err = lock.write(outputDir)
+	}
+	if err != nil {
+		fmt.Printf("❌ Error writing %s: %v\n", lockFileName, err)
+		os.Exit(1)
+	}
+	if commit {
+		if err := commitGit(outputDir, gitOpts); err != nil {
+			fmt.Printf("❌ Error setting up git: %v\n", err)
+			os.Exit(1)
 		}
+	}
+}
 
-		relPath, _ := filepath.Rel(templatePath, path)
-		tmplPath, err := templatePathFunc(relPath, values)
-		if err != nil {
-			return err
+// setupProject makes the scripts executable, generates the swagger docs,
+// tidies the module, adds it to the workspace and runs wire, each when the
+// project has what it needs.
+func setupProject(outputDir string, ws *workspace) error {
+	return inDir(outputDir, func() error {
+		// Make the scripts executable
+		if fileExists("bin") {
+			if err := runCommand("chmod +x bin/*"); err != nil {
+				return err
+			}
 		}
-		targetPath := filepath.Join(outputDir, tmplPath)
 
-		if d.IsDir() {
-			return os.MkdirAll(targetPath, 0755)
+		// Run swagger.sh if it exists
+		if fileExists("bin/swagger.sh") {
+			if err := runCommand("./bin/swagger.sh"); err != nil {
+				return err
+			}
 		}
 
-		data, err := templateFS.ReadFile(path)
-		check(err)
+		// Run post-scaffold commands (only if they exist)
+		if fileExists("go.mod") {
+			if err := runCommand("go mod tidy"); err != nil {
+				return err
+			}
+		}
 
-		if filepath.Ext(path) == ".tmpl" {
-			// Special handling for gitignore.tmpl -> .gitignore
-			if strings.HasSuffix(path, "gitignore.tmpl") {
-				gitignorePath := filepath.Join(filepath.Dir(targetPath), ".gitignore")
-				return renderTemplateBytes(data, gitignorePath, values)
+		// Add the module to go.work once tidy settled its go version, wire
+		// builds it in workspace mode
+		if ws != nil && fileExists("go.mod") {
+			relDir, err := filepath.Rel(ws.Dir, outputDir)
+			if err != nil {
+				return err
 			}
-			// Special handling for gitkeep.tmpl -> .gitkeep
-			if strings.HasSuffix(path, "gitkeep.tmpl") {
-				gitkeepPath := filepath.Join(filepath.Dir(targetPath), ".gitkeep")
-				return renderTemplateBytes(data, gitkeepPath, values)
+			if err := runCommand(fmt.Sprintf("cd %q && go work use %q", ws.Dir, "./"+filepath.ToSlash(relDir))); err != nil {
+				return err
 			}
-			// Regular template files: remove .tmpl extension
-			return renderTemplateBytes(data, targetPath[:len(targetPath)-5], values)
-		} else {
-			return os.WriteFile(targetPath, data, 0644)
 		}
+
+		// Run wire.sh if it exists
+		if fileExists("bin/wire.sh") {
+			return runCommand("./bin/wire.sh")
+		}
+		return nil
 	})
-	check(err)
+}
 
-	fmt.Printf("✅ %s project scaffolded: %s\n", strings.Title(templateType), outputDir)
+// inDir runs fn with dir as the working directory.
+func inDir(dir string, fn func() error) error {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	defer os.Chdir(originalDir)
+	return fn()
+}
 
-	// Change to output directory for running commands
-	originalDir, _ := os.Getwd()
-	check(os.Chdir(outputDir))
+// renderTree renders every file below templatePath of fsys into outputDir.
+// Entries whose name starts with "_" (e.g. _components) are never rendered;
+// a leading "__" stands for a literal "_" (e.g. __helpers.tpl for Helm's
+// _helpers.tpl).
+func renderTree(fsys fs.FS, templatePath string, outputDir string, values Values, partials *template.Template) error {
+	return fs.WalkDir(fsys, templatePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == templatePath {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), "_") && !strings.HasPrefix(d.Name(), "__") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if err := renderTreeEntry(fsys, templatePath, outputDir, values, partials, path, d); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	})
+}
 
-	// Run swagger.sh if it exists
-	if fileExists("bin/swagger.sh") {
-		runCommand("chmod +x bin/*")
-		runCommand("./bin/swagger.sh")
+// renderTreeEntry renders the file or creates the directory at path.
+// Templates can call the named templates of partials.
+func renderTreeEntry(fsys fs.FS, templatePath string, outputDir string, values Values, partials *template.Template, path string, d fs.DirEntry) error {
+	relPath, _ := filepath.Rel(templatePath, path)
+	tmplPath, err := templatePathFunc(relPath, values)
+	if err != nil {
+		return err
+	}
+	targetPath := filepath.Join(outputDir, tmplPath)
+	if name := filepath.Base(targetPath); strings.HasPrefix(name, "__") {
+		targetPath = filepath.Join(filepath.Dir(targetPath), name[1:])
 	}
 
-	// Run post-scaffold commands (only if they exist)
-	if fileExists("go.mod") {
-		runCommand("go mod tidy")
+	if d.IsDir() {
+		return os.MkdirAll(targetPath, 0755)
 	}
 
-	// Run wire.sh if it exists
-	if fileExists("bin/wire.sh") {
-		runCommand("./bin/wire.sh")
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
 	}
 
-	// Return to original directory
-	check(os.Chdir(originalDir))
+	if filepath.Ext(path) == ".tmpl" {
+		// Special handling for gitignore.tmpl -> .gitignore
+		if strings.HasSuffix(path, "gitignore.tmpl") {
+			gitignorePath := filepath.Join(filepath.Dir(targetPath), ".gitignore")
+			return renderTemplateBytes(data, gitignorePath, values, partials)
+		}
+		// Special handling for dockerignore.tmpl -> .dockerignore
+		if strings.HasSuffix(path, "dockerignore.tmpl") {
+			dockerignorePath := filepath.Join(filepath.Dir(targetPath), ".dockerignore")
+			return renderTemplateBytes(data, dockerignorePath, values, partials)
+		}
+		// Special handling for gitkeep.tmpl -> .gitkeep
+		if strings.HasSuffix(path, "gitkeep.tmpl") {
+			gitkeepPath := filepath.Join(filepath.Dir(targetPath), ".gitkeep")
+			return renderTemplateBytes(data, gitkeepPath, values, partials)
+		}
+		// Regular template files: remove .tmpl extension
+		return renderTemplateBytes(data, targetPath[:len(targetPath)-5], values, partials)
+	} else if filepath.Ext(path) == ".btmpl" {
+		// Files with their own {{ }} (e.g. Helm templates) use [[ ]]
+		return renderTemplateBytesDelims(data, strings.TrimSuffix(targetPath, ".btmpl"), values, partials, "[[", "]]")
+	} else {
+		return os.WriteFile(targetPath, data, 0644)
+	}
 }
 
-func runCommand(cmdStr string) {
+func runCommand(cmdStr string) error {
 	fmt.Println("⚙️  Running:", cmdStr)
 	cmd := exec.Command("bash", "-c", cmdStr)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	check(cmd.Run())
+	return cmd.Run()
 }
 
-func loadValues() Values {
+// loadValues merges values.yaml, the flags and the defaults of the user
+// configuration, in that order of precedence: flags first.
+func loadValues(cfg *userConfig) Values {
 	values := Values{}
 
 	// Try to load from values.yaml if it exists
@@ -379,18 +961,48 @@ func loadValues() Values {
 	if goVersion != "" {
 		values.GoVersion = goVersion
 	}
-
-	// Validate required values
-	if values.ModuleName == "" {
-		fmt.Println("❌ Module name is required. Use -m flag or provide in values.yaml")
-		os.Exit(1)
+	if len(components) > 0 {
+		values.Components = components
+	}
+	if licenseID != "" {
+		values.License = licenseID
+	}
+	if licenseHolder != "" {
+		values.LicenseHolder = licenseHolder
+	}
+	if licenseHeaders {
+		values.LicenseHeaders = true
+	}
+	if values.License == "" {
+		values.License = cfg.License
+	}
+	if strings.EqualFold(values.License, "none") {
+		values.License = ""
 	}
+	if values.LicenseHolder == "" {
+		values.LicenseHolder = cfg.LicenseHolder
+	}
+	if values.LicenseHolder == "" {
+		values.LicenseHolder = cfg.authorName()
+	}
+	for name, value := range setVars {
+		if values.Vars == nil {
+			values.Vars = map[string]string{}
+		}
+		values.Vars[name] = value
+	}
+
+	// Validate required values, the module name is checked once the output
+	// directory is known as it can come from a workspace
 	if values.RepoName == "" {
 		fmt.Println("❌ Repository name is required. Use -r flag or provide in values.yaml")
 		os.Exit(1)
 	}
 	if values.GoVersion == "" {
-		values.GoVersion = "1.24" // Default Go version
+		values.GoVersion = cfg.GoVersion
+	}
+	if values.GoVersion == "" {
+		values.GoVersion = minGoVersion // Default Go version
 		fmt.Printf("ℹ️  Using default Go version: %s\n", values.GoVersion)
 	}
 
@@ -403,11 +1015,19 @@ func loadValues() Values {
 	return values
 }
 
-func renderTemplateBytes(content []byte, outputPath string, values Values) error {
-	tmpl, err := template.New("file").Funcs(template.FuncMap{
-		"sanitize": sanitize,
-	}).Parse(string(content))
-	check(err)
+func renderTemplateBytes(content []byte, outputPath string, values Values, partials *template.Template) error {
+	return renderTemplateBytesDelims(content, outputPath, values, partials, "{{", "}}")
+}
+
+func renderTemplateBytesDelims(content []byte, outputPath string, values Values, partials *template.Template, left string, right string) error {
+	set, err := partials.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl, err := set.New("file").Delims(left, right).Parse(string(content))
+	if err != nil {
+		return err
+	}
 
 	out, err := os.Create(outputPath)
 	check(err)
@@ -423,9 +1043,7 @@ func check(err error) {
 }
 
 func templatePathFunc(path string, data Values) (string, error) {
-	tmpl, err := template.New("path").Funcs(template.FuncMap{
-		"sanitize": sanitize,
-	}).Parse(path)
+	tmpl, err := template.New("path").Funcs(templateFuncs).Option("missingkey=error").Parse(path)
 	if err != nil {
 		return "", err
 	}
@@ -439,20 +1057,6 @@ func templatePathFunc(path string, data Values) (string, error) {
 	return buf.String(), nil
 }
 
-func templateTypeExists(templateType string) bool {
-	entries, err := templateFS.ReadDir("template")
-	if err != nil {
-		return false
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() && entry.Name() == templateType {
-			return true
-		}
-	}
-	return false
-}
-
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -479,6 +1083,28 @@ func isValidGoVersion(version string) bool {
 	return major > 1 || (major == 1 && minor >= 24)
 }
 
+// compareGoVersions compares Go versions like "1.24" and "1.24.1", returning
+// -1, 0 or 1.
+func compareGoVersions(a string, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numberA, numberB int
+		if i < len(partsA) {
+			numberA, _ = parseVersionPart(partsA[i])
+		}
+		if i < len(partsB) {
+			numberB, _ = parseVersionPart(partsB[i])
+		}
+		switch {
+		case numberA < numberB:
+			return -1
+		case numberA > numberB:
+			return 1
+		}
+	}
+	return 0
+}
+
 func parseVersionPart(part string) (int, error) {
 	// Remove any non-numeric suffix
 	cleanPart := strings.TrimRightFunc(part, func(r rune) bool {
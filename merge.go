@@ -0,0 +1,1273 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A merge carries the changes between two renders of a file, before and
+// after a change of the values, over to the file of the project, which its
+// owners may have edited since it was generated. Both merges below compare
+// the syntax trees of the three files: the parts the renders agree on stay
+// as the project has them, a part the project left as rendered is replaced
+// by its new rendering, and the parts the new render adds are inserted next
+// to their neighbours. A part changed both in the project and by the new
+// render is a conflict, reported rather than guessed.
+
+// textEdit replaces the bytes from Start to End of a file with Text.
+type textEdit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// applyTextEdits applies edits, in the order they were made for those at
+// the same offset, and fails when two of them overlap.
+func applyTextEdits(src []byte, edits []textEdit) ([]byte, error) {
+	order := make([]int, len(edits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := edits[order[i]], edits[order[j]]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.End < b.End
+	})
+	var out bytes.Buffer
+	last := 0
+	for _, i := range order {
+		edit := edits[i]
+		if edit.Start < last {
+			return nil, fmt.Errorf("overlapping changes at offset %d", edit.Start)
+		}
+		out.Write(src[last:edit.Start])
+		out.WriteString(edit.Text)
+		last = edit.End
+	}
+	out.Write(src[last:])
+	return out.Bytes(), nil
+}
+
+// normalizeSpace collapses the white space of s, so that code compares
+// equal whatever its alignment.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mergeGo merges the changes from old to new, two renders of the Go file
+// name, into project. It returns the merged file, formatted, or the
+// conflicts that prevent it.
+func mergeGo(name string, old []byte, new []byte, project []byte) ([]byte, []string) {
+	merged, conflicts := mergeGoOnce(name, old, new, project)
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	// Merged into the old render itself, the changes must give the new one:
+	// anything else is a change the merge does not see.
+	check, conflicts := mergeGoOnce(name, old, new, old)
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	got, err := goContent(check)
+	want, wantErr := goContent(new)
+	if err != nil || wantErr != nil || got != want {
+		return nil, []string{fmt.Sprintf("%s: the component changes it in a way that cannot be merged", name)}
+	}
+	return merged, nil
+}
+
+// goContent returns the Go file src for a comparison: its imports sorted
+// on lines of their own, as the merge may group them otherwise than the
+// render does, then the rest of the file formatted, its white space
+// collapsed.
+func goContent(src []byte) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	var imports []string
+	end := file.Name.End()
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			for _, spec := range gen.Specs {
+				imports = append(imports, importKey(spec.(*ast.ImportSpec)))
+			}
+			end = gen.End()
+		}
+	}
+	sort.Strings(imports)
+	head := src[:fset.Position(file.Name.End()).Offset]
+	rest, err := format.Source([]byte(string(head) + "\n" + string(src[fset.Position(end).Offset:])))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(imports, "\n") + "\n" + normalizeSpace(string(rest)), nil
+}
+
+func mergeGoOnce(name string, old []byte, new []byte, project []byte) ([]byte, []string) {
+	fset := token.NewFileSet()
+	m := &goMerge{name: name}
+	var err error
+	if m.old, err = parseGoSource(fset, "old/"+name, old); err != nil {
+		return nil, []string{fmt.Sprintf("%s: rendering of the template: %v", name, err)}
+	}
+	if m.new, err = parseGoSource(fset, "new/"+name, new); err != nil {
+		return nil, []string{fmt.Sprintf("%s: rendering of the template: %v", name, err)}
+	}
+	if m.project, err = parseGoSource(fset, name, project); err != nil {
+		return nil, []string{fmt.Sprintf("%s: %v", name, err)}
+	}
+	m.mergeFile()
+	if len(m.conflicts) > 0 {
+		return nil, m.conflicts
+	}
+	merged, err := applyTextEdits(project, m.edits)
+	if err == nil {
+		// Formatted files stay formatted, the others as their owners have them
+		if formatted, fmtErr := format.Source(project); fmtErr == nil && bytes.Equal(formatted, project) {
+			merged, err = format.Source(merged)
+		} else {
+			_, err = parser.ParseFile(token.NewFileSet(), name, merged, parser.ParseComments)
+		}
+	}
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: the merged file is not valid Go: %v", name, err)}
+	}
+	return merged, nil
+}
+
+// goSource is a parsed Go file with its source.
+type goSource struct {
+	src  []byte
+	file *ast.File
+	tf   *token.File
+}
+
+func parseGoSource(fset *token.FileSet, name string, src []byte) (*goSource, error) {
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return &goSource{src: src, file: file, tf: fset.File(file.Pos())}, nil
+}
+
+func (s *goSource) offset(pos token.Pos) int {
+	return s.tf.Offset(pos)
+}
+
+func (s *goSource) line(pos token.Pos) int {
+	return s.tf.Line(pos)
+}
+
+// lineStart returns the offset of the line of offset.
+func (s *goSource) lineStart(offset int) int {
+	return bytes.LastIndexByte(s.src[:offset], '\n') + 1
+}
+
+// nextLineStart returns the offset of the line after the one of offset.
+func (s *goSource) nextLineStart(offset int) int {
+	i := bytes.IndexByte(s.src[offset:], '\n')
+	if i < 0 {
+		return len(s.src)
+	}
+	return offset + i + 1
+}
+
+// indent returns the white space starting the line of pos.
+func (s *goSource) indent(pos token.Pos) string {
+	line := s.src[s.lineStart(s.offset(pos)):]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// goSpan is a node with the comments around it that go with it: its doc,
+// the comments on the lines right above it and the one ending its line.
+type goSpan struct {
+	Node  ast.Node
+	Start token.Pos
+	End   token.Pos
+}
+
+func (s *goSource) text(span goSpan) string {
+	return string(s.src[s.offset(span.Start):s.offset(span.End)])
+}
+
+func (s *goSource) norm(span goSpan) string {
+	return normalizeSpace(s.text(span))
+}
+
+// spans returns the spans of nodes, the elements of a list between after
+// and before.
+func (s *goSource) spans(nodes []ast.Node, after token.Pos, before token.Pos) []goSpan {
+	spans := make([]goSpan, len(nodes))
+	for i, node := range nodes {
+		prev, next := after, before
+		if i > 0 {
+			prev = nodes[i-1].End()
+		}
+		if i+1 < len(nodes) {
+			next = nodes[i+1].Pos()
+		}
+		spans[i] = s.span(node, prev, next)
+	}
+	return spans
+}
+
+func (s *goSource) span(node ast.Node, prev token.Pos, next token.Pos) goSpan {
+	span := goSpan{Node: node, Start: node.Pos(), End: node.End()}
+	var doc *ast.CommentGroup
+	switch node := node.(type) {
+	case *ast.FuncDecl:
+		doc = node.Doc
+	case *ast.GenDecl:
+		doc = node.Doc
+	case *ast.Field:
+		doc = node.Doc
+	case *ast.ImportSpec:
+		doc = node.Doc
+	case *ast.ValueSpec:
+		doc = node.Doc
+	case *ast.TypeSpec:
+		doc = node.Doc
+	}
+	if doc != nil {
+		span.Start = doc.Pos()
+	}
+	// The comments up to the line of the node and after it on its line
+	for i := len(s.file.Comments) - 1; i >= 0; i-- {
+		group := s.file.Comments[i]
+		if group.End() <= span.Start && group.Pos() > prev && s.line(group.End())+1 >= s.line(span.Start) && (!prev.IsValid() || s.line(group.Pos()) > s.line(prev)) {
+			span.Start = group.Pos()
+		}
+	}
+	for _, group := range s.file.Comments {
+		if group.Pos() >= span.End && (!next.IsValid() || group.End() <= next) && s.line(group.Pos()) == s.line(span.End) {
+			span.End = group.End()
+		}
+	}
+	return span
+}
+
+// goList is a list of a Go file, between the brackets Open and Close. The
+// declarations of the file have none, they start After the imports.
+type goList struct {
+	Nodes []ast.Node
+	Open  token.Pos
+	Close token.Pos
+	After token.Pos
+	Comma bool
+}
+
+func (l goList) start() token.Pos {
+	if l.Open.IsValid() {
+		return l.Open
+	}
+	return l.After
+}
+
+type goMerge struct {
+	name      string
+	old       *goSource
+	new       *goSource
+	project   *goSource
+	edits     []textEdit
+	conflicts []string
+}
+
+func (m *goMerge) conflict(where string, format string, args ...interface{}) {
+	if where != "" {
+		where += ": "
+	}
+	m.conflicts = append(m.conflicts, fmt.Sprintf("%s: %s%s", m.name, where, fmt.Sprintf(format, args...)))
+}
+
+// newText returns the text of span of the new render, its lines after the
+// first indented by indent instead of the indentation of its first line.
+func (m *goMerge) newText(span goSpan, indent string) string {
+	from := m.new.indent(span.Start)
+	lines := strings.Split(m.new.text(span), "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], from) {
+			lines[i] = indent + lines[i][len(from):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *goMerge) insert(offset int, text string) {
+	m.edits = append(m.edits, textEdit{Start: offset, End: offset, Text: text})
+}
+
+func (m *goMerge) mergeFile() {
+	if m.old.file.Name.Name != m.new.file.Name.Name {
+		m.conflict("package", "the component renames the package")
+		return
+	}
+	m.mergeImports()
+
+	decls := func(s *goSource) goList {
+		list := goList{After: s.file.Name.End()}
+		for _, decl := range s.file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+				list.After = gen.End()
+				continue
+			}
+			list.Nodes = append(list.Nodes, decl)
+		}
+		return list
+	}
+	m.mergeList("", decls(m.old), decls(m.new), decls(m.project))
+}
+
+// importSpecs returns the imports of s and the declaration of the
+// parenthesized import block of s, if it has one.
+func importSpecs(s *goSource) ([]ast.Node, *ast.GenDecl) {
+	var specs []ast.Node
+	var block *ast.GenDecl
+	for _, decl := range s.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() && block == nil {
+			block = gen
+		}
+		for _, spec := range gen.Specs {
+			specs = append(specs, spec)
+		}
+	}
+	return specs, block
+}
+
+func (m *goMerge) mergeImports() {
+	oldSpecs, _ := importSpecs(m.old)
+	newSpecs, _ := importSpecs(m.new)
+	projectSpecs, block := importSpecs(m.project)
+	if block != nil {
+		m.mergeList("imports", goList{Nodes: oldSpecs}, goList{Nodes: newSpecs}, goList{Nodes: specNodes(block.Specs), Open: block.Lparen, Close: block.Rparen})
+		return
+	}
+
+	// Without an import block the new imports get one of their own
+	have := map[string]bool{}
+	for _, spec := range projectSpecs {
+		have[importKey(spec.(*ast.ImportSpec))] = true
+	}
+	for _, spec := range oldSpecs {
+		have[importKey(spec.(*ast.ImportSpec))] = true
+	}
+	var added []string
+	for _, spec := range newSpecs {
+		if !have[importKey(spec.(*ast.ImportSpec))] {
+			added = append(added, m.new.text(m.new.span(spec, token.NoPos, token.NoPos)))
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	after := m.project.file.Name.End()
+	if len(projectSpecs) > 0 {
+		after = projectSpecs[len(projectSpecs)-1].End()
+	}
+	m.insert(m.project.nextLineStart(m.project.offset(after)), "\nimport (\n"+strings.Join(added, "\n")+"\n)\n")
+}
+
+func importKey(spec *ast.ImportSpec) string {
+	key := spec.Path.Value
+	if spec.Name != nil {
+		key = spec.Name.Name + " " + key
+	}
+	return key
+}
+
+// shape names a node of a list for the messages and tells a changed node
+// from an added one: it is the same before and after a change.
+func (s *goSource) shape(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.FuncDecl:
+		name := node.Name.Name
+		if node.Recv != nil && len(node.Recv.List) > 0 {
+			name = "(" + s.exprText(node.Recv.List[0].Type) + ")." + name
+		}
+		return "func " + name
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range node.Specs {
+			names = append(names, s.shape(spec))
+		}
+		return node.Tok.String() + " " + strings.Join(names, ", ")
+	case *ast.TypeSpec:
+		return node.Name.Name
+	case *ast.ValueSpec:
+		return identNames(node.Names)
+	case *ast.ImportSpec:
+		return importKey(node)
+	case *ast.Field:
+		if len(node.Names) > 0 {
+			return identNames(node.Names)
+		}
+		return s.exprText(node.Type)
+	case *ast.ExprStmt:
+		return s.shape(node.X)
+	case *ast.AssignStmt:
+		var lhs []string
+		for _, expr := range node.Lhs {
+			lhs = append(lhs, s.exprText(expr))
+		}
+		return strings.Join(lhs, ", ") + " " + node.Tok.String()
+	case *ast.ReturnStmt:
+		return "return"
+	case *ast.IfStmt:
+		return "if " + s.exprText(node.Cond)
+	case *ast.DeclStmt:
+		return s.shape(node.Decl)
+	case *ast.CallExpr:
+		if sel, ok := node.Fun.(*ast.SelectorExpr); ok && strings.Contains(s.exprText(sel.X), "(") {
+			// The last call of a chain
+			return "..." + sel.Sel.Name + "(...)"
+		}
+		return s.exprText(node.Fun) + "(...)"
+	case *ast.BasicLit:
+		return strings.ToLower(node.Kind.String()) + " literal"
+	case *ast.CompositeLit:
+		if node.Type != nil {
+			return s.exprText(node.Type) + "{...}"
+		}
+		return "{...}"
+	case *ast.KeyValueExpr:
+		return s.exprText(node.Key)
+	case *ast.UnaryExpr:
+		return node.Op.String() + s.shape(node.X)
+	}
+	return normalizeSpace(string(s.src[s.offset(node.Pos()):s.offset(node.End())]))
+}
+
+func (s *goSource) exprText(node ast.Node) string {
+	return normalizeSpace(string(s.src[s.offset(node.Pos()):s.offset(node.End())]))
+}
+
+func identNames(idents []*ast.Ident) string {
+	var names []string
+	for _, ident := range idents {
+		names = append(names, ident.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// mergeList merges the changes from the list o to the list n into p: the
+// elements are aligned by their text, a removed and an added element of the
+// same shape being one changed element.
+func (m *goMerge) mergeList(where string, o goList, n goList, p goList) {
+	oSpans := m.old.spans(o.Nodes, o.start(), o.Close)
+	nSpans := m.new.spans(n.Nodes, n.start(), n.Close)
+	pSpans := m.project.spans(p.Nodes, p.start(), p.Close)
+	oKeys := make([]string, len(oSpans))
+	for i, span := range oSpans {
+		oKeys[i] = m.old.norm(span)
+	}
+	nKeys := make([]string, len(nSpans))
+	for i, span := range nSpans {
+		nKeys[i] = m.new.norm(span)
+	}
+
+	// changed maps an element of n to the element of o it changes, added
+	// marks those of n that are new.
+	changed := map[int]int{}
+	added := map[int]bool{}
+	x, y := 0, 0
+	edits := diffLines(oKeys, nKeys)
+	for i := 0; i < len(edits); {
+		if edits[i].Op == ' ' {
+			x++
+			y++
+			i++
+			continue
+		}
+		var removedRun, addedRun []int
+		for ; i < len(edits) && edits[i].Op != ' '; i++ {
+			if edits[i].Op == '-' {
+				removedRun = append(removedRun, x)
+				x++
+			} else {
+				addedRun = append(addedRun, y)
+				y++
+			}
+		}
+		paired := map[int]bool{}
+		for _, j := range addedRun {
+			added[j] = true
+			for _, k := range removedRun {
+				if !paired[k] && m.old.shape(oSpans[k].Node) == m.new.shape(nSpans[j].Node) {
+					paired[k] = true
+					changed[j] = k
+					delete(added, j)
+					break
+				}
+			}
+		}
+		// What is left pairs up in order, a changed literal for one
+		for _, k := range removedRun {
+			if paired[k] {
+				continue
+			}
+			for _, j := range addedRun {
+				if added[j] {
+					paired[k] = true
+					changed[j] = k
+					delete(added, j)
+					break
+				}
+			}
+			if !paired[k] {
+				verb := "removes"
+				if containsString(nKeys, oKeys[k]) {
+					verb = "moves"
+				}
+				m.conflict(where, "the component %s %s, which is not supported", verb, m.old.shape(oSpans[k].Node))
+			}
+		}
+	}
+
+	used := map[int]bool{}
+	find := func(match func(goSpan) bool) (goSpan, bool) {
+		for i, span := range pSpans {
+			if !used[i] && match(span) {
+				used[i] = true
+				return span, true
+			}
+		}
+		return goSpan{}, false
+	}
+	var anchor *goSpan
+	inserted := 0
+	for j, nSpan := range nSpans {
+		oIndex, isChanged := changed[j]
+		switch {
+		case isChanged:
+			oSpan := oSpans[oIndex]
+			shape := m.old.shape(oSpan.Node)
+			pSpan, ok := find(func(span goSpan) bool { return m.project.norm(span) == oKeys[oIndex] })
+			if !ok {
+				pSpan, ok = find(func(span goSpan) bool { return m.project.shape(span.Node) == shape })
+			}
+			if !ok {
+				m.conflict(where, "%s is not in the project anymore", shape)
+				continue
+			}
+			inner := shape
+			if where != "" {
+				inner = where + ": " + shape
+			}
+			m.mergeNode(inner, oSpan, nSpan, pSpan)
+			anchor = &pSpan
+		case added[j]:
+			if pSpan, ok := find(func(span goSpan) bool { return m.project.norm(span) == nKeys[j] }); ok {
+				anchor = &pSpan
+				continue
+			}
+			if shape := m.new.shape(nSpan.Node); declares(nSpan.Node) {
+				if _, ok := find(func(span goSpan) bool { return m.project.shape(span.Node) == shape }); ok {
+					m.conflict(where, "the project has its own %s", shape)
+					continue
+				}
+			}
+			after, before := anchor, (*goSpan)(nil)
+			if spec, ok := nSpan.Node.(*ast.ImportSpec); ok {
+				after, before = m.importPlace(pSpans, spec, anchor)
+			}
+			m.insertElement(p, pSpans, after, before, nSpan, inserted)
+			inserted++
+		default:
+			pSpan, ok := find(func(span goSpan) bool { return m.project.norm(span) == nKeys[j] })
+			if !ok && declares(nSpan.Node) {
+				// The project edited it, its name still places the next ones
+				shape := m.new.shape(nSpan.Node)
+				pSpan, ok = find(func(span goSpan) bool { return m.project.shape(span.Node) == shape })
+			}
+			if ok {
+				anchor = &pSpan
+			}
+		}
+	}
+}
+
+// declares reports whether node declares a name, which a list cannot have
+// twice: a declaration, a field or the key of a composite literal.
+func declares(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.FuncDecl, *ast.GenDecl, *ast.TypeSpec, *ast.ValueSpec, *ast.Field, *ast.KeyValueExpr:
+		return true
+	}
+	return false
+}
+
+// importPlace returns where the import spec goes in the imports pSpans of
+// the project: after the last of those sharing most leading elements of its
+// path which sorts before it, or before the first of them, so that it joins
+// their group. It is after anchor when no import shares any.
+func (m *goMerge) importPlace(pSpans []goSpan, spec *ast.ImportSpec, anchor *goSpan) (after *goSpan, before *goSpan) {
+	importPath := strings.Split(strings.Trim(spec.Path.Value, "`\""), "/")
+	most := 0
+	for i := range pSpans {
+		pSpec, ok := pSpans[i].Node.(*ast.ImportSpec)
+		if !ok {
+			continue
+		}
+		shared := 0
+		for _, element := range strings.Split(strings.Trim(pSpec.Path.Value, "`\""), "/") {
+			if shared == len(importPath) || element != importPath[shared] {
+				break
+			}
+			shared++
+		}
+		switch {
+		case shared == 0 || shared < most:
+		case shared > most:
+			most, after, before = shared, nil, nil
+			fallthrough
+		default:
+			if pSpec.Path.Value < spec.Path.Value {
+				after = &pSpans[i]
+			} else if before == nil && after == nil {
+				before = &pSpans[i]
+			}
+		}
+	}
+	if most == 0 {
+		return anchor, nil
+	}
+	if after != nil {
+		return after, nil
+	}
+	return nil, before
+}
+
+// insertElement inserts text into the list p after anchor, or before the
+// element before, the first one when there is neither, as the list is laid
+// out: one element per line or all on the line of the brackets. count is
+// the number of elements inserted into p before.
+func (m *goMerge) insertElement(p goList, pSpans []goSpan, anchor *goSpan, before *goSpan, span goSpan, count int) {
+	s := m.project
+	if anchor == nil && before == nil && len(pSpans) > 0 {
+		before = &pSpans[0]
+	}
+	// Indented as the elements of the project, or as in the new render
+	indent := m.new.indent(span.Start)
+	if anchor != nil {
+		indent = s.indent(anchor.Start)
+	} else if before != nil {
+		indent = s.indent(before.Start)
+	}
+	text := m.newText(span, indent)
+	lineText := indent + text
+	separator := "\n"
+	if p.Comma {
+		separator = ", "
+	}
+	multiLine := !p.Open.IsValid() || s.line(p.Open) != s.line(p.Close)
+	term := "\n"
+	if p.Comma {
+		term = ",\n"
+	}
+	if !p.Open.IsValid() {
+		// Declarations of the file, a blank line between them
+		switch {
+		case anchor != nil:
+			m.insert(s.nextLineStart(s.offset(anchor.End)), "\n"+text+"\n")
+		case before != nil:
+			m.insert(s.lineStart(s.offset(before.Start)), text+"\n\n")
+		default:
+			m.insert(len(s.src), "\n"+text+"\n")
+		}
+		return
+	}
+
+	switch {
+	case anchor != nil && multiLine && s.line(anchor.End) != s.line(p.Close):
+		m.insert(s.nextLineStart(s.offset(anchor.End)), lineText+term)
+	case anchor != nil:
+		m.insert(s.offset(anchor.End), separator+text)
+	case before != nil && multiLine && s.line(before.Start) != s.line(p.Open):
+		m.insert(s.lineStart(s.offset(before.Start)), lineText+term)
+	case before != nil:
+		m.insert(s.offset(before.Start), text+separator)
+	case multiLine:
+		m.insert(s.lineStart(s.offset(p.Close)), lineText+term)
+	case count > 0:
+		m.insert(s.offset(p.Open)+1, separator+text)
+	case p.Comma:
+		m.insert(s.offset(p.Open)+1, text)
+	default:
+		m.insert(s.offset(p.Open)+1, "\n"+text+"\n")
+	}
+}
+
+func isNilNode(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// mergeChild merges the changes of a node which is no element of a list,
+// the condition of an if for one.
+func (m *goMerge) mergeChild(where string, o ast.Node, n ast.Node, p ast.Node) {
+	oNil, nNil, pNil := isNilNode(o), isNilNode(n), isNilNode(p)
+	if oNil && nNil {
+		return
+	}
+	if !oNil && !nNil && m.old.exprText(o) == m.new.exprText(n) {
+		return
+	}
+	if oNil || nNil || pNil {
+		m.conflict(where, "the component changes a part of it the merge does not support")
+		return
+	}
+	m.mergeNode(where, goSpan{o, o.Pos(), o.End()}, goSpan{n, n.Pos(), n.End()}, goSpan{p, p.Pos(), p.End()})
+}
+
+func (m *goMerge) mergeChildren(where string, o []ast.Expr, n []ast.Expr, p []ast.Expr) {
+	if len(o) != len(n) || len(o) != len(p) {
+		m.conflict(where, "changed both in the project and by the component")
+		return
+	}
+	for i := range o {
+		m.mergeChild(where, o[i], n[i], p[i])
+	}
+}
+
+// mergeNode merges the change from o to n into p: p is replaced when the
+// project left it as it was rendered, otherwise the change is looked for in
+// its parts.
+func (m *goMerge) mergeNode(where string, o goSpan, n goSpan, p goSpan) {
+	if m.old.norm(o) == m.new.norm(n) {
+		return
+	}
+	if m.project.norm(p) == m.old.norm(o) {
+		m.edits = append(m.edits, textEdit{Start: m.project.offset(p.Start), End: m.project.offset(p.End), Text: m.newText(n, m.project.indent(p.Start))})
+		return
+	}
+	if reflect.TypeOf(o.Node) != reflect.TypeOf(n.Node) || reflect.TypeOf(o.Node) != reflect.TypeOf(p.Node) {
+		m.conflict(where, "changed both in the project and by the component")
+		return
+	}
+
+	switch on := o.Node.(type) {
+	case *ast.FuncDecl:
+		nn, pn := n.Node.(*ast.FuncDecl), p.Node.(*ast.FuncDecl)
+		m.mergeDoc(where, on.Doc, nn.Doc, pn.Doc, pn.Pos())
+		m.mergeChild(where, on.Recv, nn.Recv, pn.Recv)
+		m.mergeChild(where, on.Type, nn.Type, pn.Type)
+		m.mergeChild(where, on.Body, nn.Body, pn.Body)
+	case *ast.GenDecl:
+		nn, pn := n.Node.(*ast.GenDecl), p.Node.(*ast.GenDecl)
+		m.mergeDoc(where, on.Doc, nn.Doc, pn.Doc, pn.Pos())
+		switch {
+		case on.Lparen.IsValid() && nn.Lparen.IsValid() && pn.Lparen.IsValid():
+			m.mergeList(where, goList{Nodes: specNodes(on.Specs), Open: on.Lparen, Close: on.Rparen},
+				goList{Nodes: specNodes(nn.Specs), Open: nn.Lparen, Close: nn.Rparen},
+				goList{Nodes: specNodes(pn.Specs), Open: pn.Lparen, Close: pn.Rparen})
+		case len(on.Specs) == 1 && len(nn.Specs) == 1 && len(pn.Specs) == 1:
+			m.mergeChild(where, on.Specs[0], nn.Specs[0], pn.Specs[0])
+		default:
+			m.conflict(where, "changed both in the project and by the component")
+		}
+	case *ast.TypeSpec:
+		nn, pn := n.Node.(*ast.TypeSpec), p.Node.(*ast.TypeSpec)
+		m.mergeChild(where, on.Type, nn.Type, pn.Type)
+	case *ast.ValueSpec:
+		nn, pn := n.Node.(*ast.ValueSpec), p.Node.(*ast.ValueSpec)
+		m.mergeChild(where, on.Type, nn.Type, pn.Type)
+		m.mergeChildren(where, on.Values, nn.Values, pn.Values)
+	case *ast.StructType:
+		m.mergeFieldList(where, on.Fields, n.Node.(*ast.StructType).Fields, p.Node.(*ast.StructType).Fields)
+	case *ast.InterfaceType:
+		m.mergeFieldList(where, on.Methods, n.Node.(*ast.InterfaceType).Methods, p.Node.(*ast.InterfaceType).Methods)
+	case *ast.FieldList:
+		m.mergeFieldList(where, on, n.Node.(*ast.FieldList), p.Node.(*ast.FieldList))
+	case *ast.FuncType:
+		nn, pn := n.Node.(*ast.FuncType), p.Node.(*ast.FuncType)
+		m.mergeChild(where, on.Params, nn.Params, pn.Params)
+		m.mergeChild(where, on.Results, nn.Results, pn.Results)
+	case *ast.FuncLit:
+		nn, pn := n.Node.(*ast.FuncLit), p.Node.(*ast.FuncLit)
+		m.mergeChild(where, on.Type, nn.Type, pn.Type)
+		m.mergeChild(where, on.Body, nn.Body, pn.Body)
+	case *ast.BlockStmt:
+		nn, pn := n.Node.(*ast.BlockStmt), p.Node.(*ast.BlockStmt)
+		m.mergeList(where, goList{Nodes: stmtNodes(on.List), Open: on.Lbrace, Close: on.Rbrace},
+			goList{Nodes: stmtNodes(nn.List), Open: nn.Lbrace, Close: nn.Rbrace},
+			goList{Nodes: stmtNodes(pn.List), Open: pn.Lbrace, Close: pn.Rbrace})
+	case *ast.ExprStmt:
+		m.mergeChild(where, on.X, n.Node.(*ast.ExprStmt).X, p.Node.(*ast.ExprStmt).X)
+	case *ast.AssignStmt:
+		nn, pn := n.Node.(*ast.AssignStmt), p.Node.(*ast.AssignStmt)
+		m.mergeChildren(where, on.Lhs, nn.Lhs, pn.Lhs)
+		m.mergeChildren(where, on.Rhs, nn.Rhs, pn.Rhs)
+	case *ast.ReturnStmt:
+		m.mergeChildren(where, on.Results, n.Node.(*ast.ReturnStmt).Results, p.Node.(*ast.ReturnStmt).Results)
+	case *ast.DeclStmt:
+		m.mergeChild(where, on.Decl, n.Node.(*ast.DeclStmt).Decl, p.Node.(*ast.DeclStmt).Decl)
+	case *ast.DeferStmt:
+		m.mergeChild(where, on.Call, n.Node.(*ast.DeferStmt).Call, p.Node.(*ast.DeferStmt).Call)
+	case *ast.GoStmt:
+		m.mergeChild(where, on.Call, n.Node.(*ast.GoStmt).Call, p.Node.(*ast.GoStmt).Call)
+	case *ast.IfStmt:
+		nn, pn := n.Node.(*ast.IfStmt), p.Node.(*ast.IfStmt)
+		m.mergeChild(where, on.Init, nn.Init, pn.Init)
+		m.mergeChild(where, on.Cond, nn.Cond, pn.Cond)
+		m.mergeChild(where, on.Body, nn.Body, pn.Body)
+		m.mergeChild(where, on.Else, nn.Else, pn.Else)
+	case *ast.ForStmt:
+		nn, pn := n.Node.(*ast.ForStmt), p.Node.(*ast.ForStmt)
+		m.mergeChild(where, on.Init, nn.Init, pn.Init)
+		m.mergeChild(where, on.Cond, nn.Cond, pn.Cond)
+		m.mergeChild(where, on.Post, nn.Post, pn.Post)
+		m.mergeChild(where, on.Body, nn.Body, pn.Body)
+	case *ast.RangeStmt:
+		nn, pn := n.Node.(*ast.RangeStmt), p.Node.(*ast.RangeStmt)
+		m.mergeChild(where, on.Key, nn.Key, pn.Key)
+		m.mergeChild(where, on.Value, nn.Value, pn.Value)
+		m.mergeChild(where, on.X, nn.X, pn.X)
+		m.mergeChild(where, on.Body, nn.Body, pn.Body)
+	case *ast.CallExpr:
+		nn, pn := n.Node.(*ast.CallExpr), p.Node.(*ast.CallExpr)
+		m.mergeChild(where, on.Fun, nn.Fun, pn.Fun)
+		m.mergeList(where, goList{Nodes: exprNodes(on.Args), Open: on.Lparen, Close: on.Rparen, Comma: true},
+			goList{Nodes: exprNodes(nn.Args), Open: nn.Lparen, Close: nn.Rparen, Comma: true},
+			goList{Nodes: exprNodes(pn.Args), Open: pn.Lparen, Close: pn.Rparen, Comma: true})
+	case *ast.CompositeLit:
+		nn, pn := n.Node.(*ast.CompositeLit), p.Node.(*ast.CompositeLit)
+		m.mergeChild(where, on.Type, nn.Type, pn.Type)
+		m.mergeList(where, goList{Nodes: exprNodes(on.Elts), Open: on.Lbrace, Close: on.Rbrace, Comma: true},
+			goList{Nodes: exprNodes(nn.Elts), Open: nn.Lbrace, Close: nn.Rbrace, Comma: true},
+			goList{Nodes: exprNodes(pn.Elts), Open: pn.Lbrace, Close: pn.Rbrace, Comma: true})
+	case *ast.KeyValueExpr:
+		nn, pn := n.Node.(*ast.KeyValueExpr), p.Node.(*ast.KeyValueExpr)
+		m.mergeChild(where, on.Key, nn.Key, pn.Key)
+		m.mergeChild(where, on.Value, nn.Value, pn.Value)
+	case *ast.SelectorExpr:
+		nn, pn := n.Node.(*ast.SelectorExpr), p.Node.(*ast.SelectorExpr)
+		if on.Sel.Name != nn.Sel.Name {
+			m.conflict(where, "changed both in the project and by the component")
+			return
+		}
+		m.mergeChild(where, on.X, nn.X, pn.X)
+	case *ast.UnaryExpr:
+		m.mergeChild(where, on.X, n.Node.(*ast.UnaryExpr).X, p.Node.(*ast.UnaryExpr).X)
+	case *ast.StarExpr:
+		m.mergeChild(where, on.X, n.Node.(*ast.StarExpr).X, p.Node.(*ast.StarExpr).X)
+	case *ast.ParenExpr:
+		m.mergeChild(where, on.X, n.Node.(*ast.ParenExpr).X, p.Node.(*ast.ParenExpr).X)
+	default:
+		m.conflict(where, "changed both in the project and by the component")
+	}
+}
+
+func (m *goMerge) mergeFieldList(where string, o *ast.FieldList, n *ast.FieldList, p *ast.FieldList) {
+	if o == nil || n == nil || p == nil {
+		m.mergeChild(where, o, n, p)
+		return
+	}
+	list := func(fields *ast.FieldList) goList {
+		var nodes []ast.Node
+		for _, field := range fields.List {
+			nodes = append(nodes, field)
+		}
+		// Parameters are separated by commas, struct fields by lines
+		return goList{Nodes: nodes, Open: fields.Opening, Close: fields.Closing, Comma: fields.Opening.IsValid() && m.isParen(fields)}
+	}
+	m.mergeList(where, list(o), list(n), list(p))
+}
+
+// isParen reports whether fields, of any of the three files, is a list of
+// parameters or results: between parentheses rather than braces.
+func (m *goMerge) isParen(fields *ast.FieldList) bool {
+	for _, s := range []*goSource{m.old, m.new, m.project} {
+		if s.tf.Base() <= int(fields.Opening) && int(fields.Opening) <= s.tf.Base()+s.tf.Size() {
+			return s.src[s.offset(fields.Opening)] == '('
+		}
+	}
+	return false
+}
+
+// mergeDoc merges the change of the doc comment of a declaration, at pos
+// in the project.
+func (m *goMerge) mergeDoc(where string, o *ast.CommentGroup, n *ast.CommentGroup, p *ast.CommentGroup, pos token.Pos) {
+	text := func(s *goSource, doc *ast.CommentGroup) string {
+		if doc == nil {
+			return ""
+		}
+		return string(s.src[s.offset(doc.Pos()):s.offset(doc.End())])
+	}
+	oText, nText, pText := text(m.old, o), text(m.new, n), text(m.project, p)
+	switch {
+	case normalizeSpace(oText) == normalizeSpace(nText):
+	case normalizeSpace(pText) != normalizeSpace(oText):
+		m.conflict(where, "the doc comment changed both in the project and by the component")
+	case p != nil && n != nil:
+		m.edits = append(m.edits, textEdit{Start: m.project.offset(p.Pos()), End: m.project.offset(p.End()), Text: nText})
+	case p == nil:
+		m.insert(m.project.lineStart(m.project.offset(pos)), nText+"\n")
+	default:
+		m.conflict(where, "the component removes the doc comment, which is not supported")
+	}
+}
+
+func specNodes(specs []ast.Spec) []ast.Node {
+	nodes := make([]ast.Node, len(specs))
+	for i, spec := range specs {
+		nodes[i] = spec
+	}
+	return nodes
+}
+
+func stmtNodes(stmts []ast.Stmt) []ast.Node {
+	nodes := make([]ast.Node, len(stmts))
+	for i, stmt := range stmts {
+		nodes[i] = stmt
+	}
+	return nodes
+}
+
+func exprNodes(exprs []ast.Expr) []ast.Node {
+	nodes := make([]ast.Node, len(exprs))
+	for i, expr := range exprs {
+		nodes[i] = expr
+	}
+	return nodes
+}
+
+// mergeYAML merges the changes from old to new, two renders of the YAML
+// file name, into project: entries added to mappings and items added to
+// block sequences are inserted after their neighbours, indented as the
+// project has them, and a value the project left as rendered is replaced.
+func mergeYAML(name string, old []byte, new []byte, project []byte) ([]byte, []string) {
+	merged, conflicts := mergeYAMLOnce(name, old, new, project)
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	check, conflicts := mergeYAMLOnce(name, old, new, old)
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	var got, want interface{}
+	if yaml.Unmarshal(check, &got) != nil || yaml.Unmarshal(new, &want) != nil || !reflect.DeepEqual(got, want) {
+		return nil, []string{fmt.Sprintf("%s: the component changes it in a way that cannot be merged", name)}
+	}
+	return merged, nil
+}
+
+func mergeYAMLOnce(name string, old []byte, new []byte, project []byte) ([]byte, []string) {
+	m := &yamlMerge{name: name}
+	var err error
+	if m.old, err = parseYAMLSource(old); err != nil {
+		return nil, []string{fmt.Sprintf("%s: rendering of the template: %v", name, err)}
+	}
+	if m.new, err = parseYAMLSource(new); err != nil {
+		return nil, []string{fmt.Sprintf("%s: rendering of the template: %v", name, err)}
+	}
+	if m.project, err = parseYAMLSource(project); err != nil {
+		return nil, []string{fmt.Sprintf("%s: %v", name, err)}
+	}
+	m.mergeValue("", nil, m.old.root, nil, m.new.root, nil, m.project.root)
+	if len(m.conflicts) > 0 {
+		return nil, m.conflicts
+	}
+	merged, err := applyTextEdits(project, m.edits)
+	if err == nil {
+		var check yaml.Node
+		err = yaml.Unmarshal(merged, &check)
+	}
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: the merged file is not valid YAML: %v", name, err)}
+	}
+	return merged, nil
+}
+
+// yamlSource is a parsed YAML document with the lines of its source.
+type yamlSource struct {
+	src   []byte
+	lines []string
+	root  *yaml.Node
+}
+
+func parseYAMLSource(src []byte) (*yamlSource, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("no YAML document")
+	}
+	return &yamlSource{src: src, lines: strings.SplitAfter(string(src), "\n"), root: doc.Content[0]}, nil
+}
+
+// offset returns the offset of the start of line, 1 for the first.
+func (s *yamlSource) offset(line int) int {
+	offset := 0
+	for i := 0; i < line-1 && i < len(s.lines); i++ {
+		offset += len(s.lines[i])
+	}
+	return offset
+}
+
+// lastLine returns the last line of node and its descendants.
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		last += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	for _, child := range node.Content {
+		if line := lastLine(child); line > last {
+			last = line
+		}
+	}
+	return last
+}
+
+// headLine returns the first line of the comment right above node, the
+// line of node when it has none.
+func (s *yamlSource) headLine(node *yaml.Node) int {
+	line := node.Line
+	for line > 1 && strings.HasPrefix(strings.TrimSpace(s.lines[line-2]), "#") {
+		line--
+	}
+	return line
+}
+
+// block returns the lines from first to the last one of end, indented by
+// delta more columns, with the blank line before first when blank is set
+// and the source has one.
+func (s *yamlSource) block(first int, end *yaml.Node, delta int, blank bool) string {
+	var out strings.Builder
+	if blank && first > 1 && strings.TrimSpace(s.lines[first-2]) == "" {
+		out.WriteString("\n")
+	}
+	for _, line := range s.lines[first-1 : lastLine(end)] {
+		switch {
+		case strings.TrimSpace(line) == "":
+		case delta > 0:
+			line = strings.Repeat(" ", delta) + line
+		case delta < 0:
+			trim := len(line) - len(strings.TrimLeft(line, " "))
+			if trim > -delta {
+				trim = -delta
+			}
+			line = line[trim:]
+		}
+		out.WriteString(line)
+	}
+	if !strings.HasSuffix(out.String(), "\n") {
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+type yamlMerge struct {
+	name      string
+	old       *yamlSource
+	new       *yamlSource
+	project   *yamlSource
+	edits     []textEdit
+	conflicts []string
+}
+
+func (m *yamlMerge) conflict(where string, format string, args ...interface{}) {
+	if where == "" {
+		where = "the document"
+	}
+	m.conflicts = append(m.conflicts, fmt.Sprintf("%s: %s: %s", m.name, where, fmt.Sprintf(format, args...)))
+}
+
+// yamlEqual reports whether a and b have the same value. Aliases are
+// compared by name: changes of their anchor are merged where it is defined.
+func yamlEqual(a *yaml.Node, b *yaml.Node) bool {
+	if a.Kind == yaml.AliasNode || b.Kind == yaml.AliasNode {
+		return a.Kind == b.Kind && a.Value == b.Value
+	}
+	var x, y interface{}
+	if a.Decode(&x) != nil || b.Decode(&y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// yamlKey is the text of a node that tells items apart.
+func yamlKey(node *yaml.Node) string {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return node.Value
+	}
+	return string(out)
+}
+
+// mergeValue merges the change from o to n, the values of an entry or
+// items of a sequence whose first line is that of the nodes oStart, nStart
+// and pStart, into p.
+func (m *yamlMerge) mergeValue(where string, oStart *yaml.Node, o *yaml.Node, nStart *yaml.Node, n *yaml.Node, pStart *yaml.Node, p *yaml.Node) {
+	if yamlEqual(o, n) {
+		return
+	}
+	// A mapping or sequence whose text the project changed, for a comment,
+	// is merged into rather than replaced
+	replace := pStart != nil && (p.Kind == yaml.ScalarNode || p.Kind != n.Kind || p.Style&yaml.FlowStyle != 0 || n.Style&yaml.FlowStyle != 0 ||
+		m.project.block(pStart.Line, p, 0, false) == m.old.block(oStart.Line, o, pStart.Column-oStart.Column, false))
+	if yamlEqual(p, o) && replace {
+		delta := pStart.Column - nStart.Column
+		m.edits = append(m.edits, textEdit{
+			Start: m.project.offset(pStart.Line),
+			End:   m.project.offset(lastLine(p) + 1),
+			Text:  m.new.block(nStart.Line, n, delta, false),
+		})
+		return
+	}
+	switch {
+	case o.Kind == yaml.MappingNode && n.Kind == yaml.MappingNode && p.Kind == yaml.MappingNode:
+		m.mergeMapping(where, o, n, p)
+	case o.Kind == yaml.SequenceNode && n.Kind == yaml.SequenceNode && p.Kind == yaml.SequenceNode:
+		m.mergeSequence(where, o, n, p)
+	default:
+		m.conflict(where, "changed both in the project and by the component")
+	}
+}
+
+func (m *yamlMerge) mergeMapping(where string, o *yaml.Node, n *yaml.Node, p *yaml.Node) {
+	if p.Style&yaml.FlowStyle != 0 || n.Style&yaml.FlowStyle != 0 {
+		m.conflict(where, "changed both in the project and by the component")
+		return
+	}
+	entry := func(mapping *yaml.Node, key string) (int, bool) {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	path := func(key string) string {
+		if where == "" {
+			return key
+		}
+		return where + "." + key
+	}
+	for i := 0; i+1 < len(o.Content); i += 2 {
+		if _, ok := entry(n, o.Content[i].Value); !ok {
+			m.conflict(where, "the component removes %s, which is not supported", o.Content[i].Value)
+		}
+	}
+
+	anchor := -1
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i].Value
+		pIndex, inProject := entry(p, key)
+		oIndex, inOld := entry(o, key)
+		switch {
+		case inOld && !inProject:
+			if !yamlEqual(o.Content[oIndex+1], n.Content[i+1]) {
+				m.conflict(path(key), "it is not in the project anymore")
+			}
+		case inOld:
+			m.mergeValue(path(key), o.Content[oIndex], o.Content[oIndex+1], n.Content[i], n.Content[i+1], p.Content[pIndex], p.Content[pIndex+1])
+		case !inProject:
+			m.insertEntry(where, p, anchor, n.Content[i], n.Content[i+1])
+		case !yamlEqual(p.Content[pIndex+1], n.Content[i+1]):
+			m.conflict(path(key), "the project has its own value")
+		}
+		if inProject {
+			anchor = pIndex
+		}
+	}
+}
+
+// insertEntry inserts the lines of the nodes from start to end of the new
+// render, with the comment above start, into the mapping or sequence p,
+// after its child at anchor or before the first when anchor is -1.
+func (m *yamlMerge) insertEntry(where string, p *yaml.Node, anchor int, start *yaml.Node, end *yaml.Node) {
+	if len(p.Content) == 0 {
+		m.conflict(where, "it is empty in the project, the component cannot add to it")
+		return
+	}
+	// Children of the project are aligned on the first one
+	delta := p.Content[0].Column - start.Column
+	if anchor < 0 {
+		first := p.Content[0]
+		m.insertLines(m.project.headLine(first), m.new.block(m.new.headLine(start), end, delta, false))
+		return
+	}
+	after := p.Content[anchor]
+	if p.Kind == yaml.MappingNode {
+		after = p.Content[anchor+1]
+	}
+	m.insertLines(lastLine(after)+1, m.new.block(m.new.headLine(start), end, delta, true))
+}
+
+func (m *yamlMerge) insertLines(line int, text string) {
+	offset := m.project.offset(line)
+	if src := m.project.src; offset == len(src) && offset > 0 && src[offset-1] != '\n' {
+		text = "\n" + text
+	}
+	m.edits = append(m.edits, textEdit{Start: offset, End: offset, Text: text})
+}
+
+func (m *yamlMerge) mergeSequence(where string, o *yaml.Node, n *yaml.Node, p *yaml.Node) {
+	if p.Style&yaml.FlowStyle != 0 || n.Style&yaml.FlowStyle != 0 {
+		m.conflict(where, "changed both in the project and by the component")
+		return
+	}
+	keys := func(nodes []*yaml.Node) []string {
+		keys := make([]string, len(nodes))
+		for i, node := range nodes {
+			keys[i] = yamlKey(node)
+		}
+		return keys
+	}
+	pKeys := keys(p.Content)
+	used := map[int]bool{}
+	find := func(key string) int {
+		for i, pKey := range pKeys {
+			if !used[i] && pKey == key {
+				used[i] = true
+				return i
+			}
+		}
+		return -1
+	}
+	anchor := -1
+	y := 0
+	for _, edit := range diffLines(keys(o.Content), keys(n.Content)) {
+		switch edit.Op {
+		case '-':
+			m.conflict(where, "the component removes or changes an item, which is not supported")
+			return
+		case ' ':
+			if i := find(edit.Line); i >= 0 {
+				anchor = i
+			}
+			y++
+		case '+':
+			if i := find(edit.Line); i >= 0 {
+				anchor = i
+			} else {
+				m.insertEntry(where, p, anchor, n.Content[y], n.Content[y])
+			}
+			y++
+		}
+	}
+}
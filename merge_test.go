@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMerge merges each case of testdata/merge: the changes from old to new,
+// two renders of the template, into project. The result is want, or the
+// merge fails with the conflicts listed in conflicts.txt.
+func TestMerge(t *testing.T) {
+	cases, err := os.ReadDir(filepath.Join("testdata", "merge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merges := map[string]func(name string, old []byte, new []byte, project []byte) ([]byte, []string){
+		".go":   mergeGo,
+		".yaml": mergeYAML,
+	}
+	for _, c := range cases {
+		dir := filepath.Join("testdata", "merge", c.Name())
+		t.Run(c.Name(), func(t *testing.T) {
+			olds, err := filepath.Glob(filepath.Join(dir, "old.*"))
+			if err != nil || len(olds) != 1 {
+				t.Fatalf("want one old file, got %q (%v)", olds, err)
+			}
+			ext := path.Ext(olds[0])
+			read := func(name string) []byte {
+				t.Helper()
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return content
+			}
+
+			name := c.Name() + ext
+			merged, conflicts := merges[ext](name, read("old"+ext), read("new"+ext), read("project"+ext))
+			if fileExists(filepath.Join(dir, "conflicts.txt")) {
+				if merged != nil {
+					t.Errorf("merged a conflicting change:\n%s", merged)
+				}
+				got := strings.Join(conflicts, "\n")
+				for _, want := range strings.Split(strings.TrimSpace(string(read("conflicts.txt"))), "\n") {
+					if !strings.Contains(got, want) {
+						t.Errorf("conflicts %q, want %q", conflicts, want)
+					}
+				}
+				return
+			}
+			if len(conflicts) > 0 {
+				t.Fatalf("conflicts: %q", conflicts)
+			}
+			if want := read("want" + ext); string(merged) != string(want) {
+				t.Errorf("got\n%s\nwant\n%s", merged, want)
+			}
+		})
+	}
+}
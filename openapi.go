@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The subset of OpenAPI 3.0/3.1 read by 'beginning add api'. Maps whose
+// order shows up in the generated code (paths, schemas, properties,
+// responses) keep the order of the spec.
+
+type openAPISpec struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Security   []map[string][]string        `yaml:"security"`
+	Paths      orderedMap[*openAPIPathItem] `yaml:"paths"`
+	Components openAPIComponents            `yaml:"components"`
+}
+
+type openAPIComponents struct {
+	Schemas         orderedMap[*openAPISchema]        `yaml:"schemas"`
+	Parameters      map[string]*openAPIParameter      `yaml:"parameters"`
+	RequestBodies   map[string]*openAPIRequestBody    `yaml:"requestBodies"`
+	Responses       map[string]*openAPIResponse       `yaml:"responses"`
+	SecuritySchemes map[string]*openAPISecurityScheme `yaml:"securitySchemes"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation   `yaml:"get"`
+	Put        *openAPIOperation   `yaml:"put"`
+	Post       *openAPIOperation   `yaml:"post"`
+	Delete     *openAPIOperation   `yaml:"delete"`
+	Options    *openAPIOperation   `yaml:"options"`
+	Head       *openAPIOperation   `yaml:"head"`
+	Patch      *openAPIOperation   `yaml:"patch"`
+}
+
+type openAPIMethod struct {
+	Method    string
+	Operation *openAPIOperation
+}
+
+// operations returns the operations of the path in a stable order.
+func (pathItem *openAPIPathItem) operations() []openAPIMethod {
+	var operations []openAPIMethod
+	for _, method := range []openAPIMethod{
+		{"GET", pathItem.Get},
+		{"POST", pathItem.Post},
+		{"PUT", pathItem.Put},
+		{"PATCH", pathItem.Patch},
+		{"DELETE", pathItem.Delete},
+		{"HEAD", pathItem.Head},
+		{"OPTIONS", pathItem.Options},
+	} {
+		if method.Operation != nil {
+			operations = append(operations, method)
+		}
+	}
+	return operations
+}
+
+type openAPIOperation struct {
+	OperationID string                       `yaml:"operationId"`
+	Summary     string                       `yaml:"summary"`
+	Description string                       `yaml:"description"`
+	Tags        []string                     `yaml:"tags"`
+	Deprecated  bool                         `yaml:"deprecated"`
+	Parameters  []*openAPIParameter          `yaml:"parameters"`
+	RequestBody *openAPIRequestBody          `yaml:"requestBody"`
+	Responses   orderedMap[*openAPIResponse] `yaml:"responses"`
+	Security    *[]map[string][]string       `yaml:"security"`
+}
+
+type openAPIParameter struct {
+	Ref         string         `yaml:"$ref"`
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description"`
+	Required    bool           `yaml:"required"`
+	Schema      *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref         string                       `yaml:"$ref"`
+	Description string                       `yaml:"description"`
+	Required    bool                         `yaml:"required"`
+	Content     map[string]*openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                       `yaml:"$ref"`
+	Description string                       `yaml:"description"`
+	Content     map[string]*openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+}
+
+type openAPISchema struct {
+	Ref                  string                     `yaml:"$ref"`
+	Type                 schemaType                 `yaml:"type"`
+	Format               string                     `yaml:"format"`
+	Description          string                     `yaml:"description"`
+	Nullable             bool                       `yaml:"nullable"`
+	Properties           orderedMap[*openAPISchema] `yaml:"properties"`
+	Required             []string                   `yaml:"required"`
+	Items                *openAPISchema             `yaml:"items"`
+	AdditionalProperties *additionalProperties      `yaml:"additionalProperties"`
+	Enum                 []interface{}              `yaml:"enum"`
+	AllOf                []*openAPISchema           `yaml:"allOf"`
+	OneOf                []*openAPISchema           `yaml:"oneOf"`
+	AnyOf                []*openAPISchema           `yaml:"anyOf"`
+	MinLength            *int                       `yaml:"minLength"`
+	MaxLength            *int                       `yaml:"maxLength"`
+	Minimum              *float64                   `yaml:"minimum"`
+	Maximum              *float64                   `yaml:"maximum"`
+	ExclusiveMinimum     interface{}                `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum     interface{}                `yaml:"exclusiveMaximum"`
+	MinItems             *int                       `yaml:"minItems"`
+	MaxItems             *int                       `yaml:"maxItems"`
+}
+
+// schemaType is "type", a string in 3.0 and a string or a list in 3.1 where
+// "null" marks the schema nullable.
+type schemaType struct {
+	Name     string
+	Nullable bool
+}
+
+func (t *schemaType) UnmarshalYAML(node *yaml.Node) error {
+	var names []string
+	if node.Kind == yaml.SequenceNode {
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+	} else {
+		names = []string{node.Value}
+	}
+	for _, name := range names {
+		if name == "null" {
+			t.Nullable = true
+		} else if t.Name == "" {
+			t.Name = name
+		}
+	}
+	return nil
+}
+
+// additionalProperties is either a boolean or a schema.
+type additionalProperties struct {
+	Allowed bool
+	Schema  *openAPISchema
+}
+
+func (a *additionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+	a.Allowed = true
+	return node.Decode(&a.Schema)
+}
+
+// orderedMap is a YAML mapping that remembers the order of its keys.
+type orderedMap[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+func (m *orderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	m.Values = map[string]T{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		if _, ok := m.Values[key]; !ok {
+			m.Keys = append(m.Keys, key)
+		}
+		m.Values[key] = value
+	}
+	return nil
+}
+
+// loadOpenAPISpec reads a YAML or JSON OpenAPI document.
+func loadOpenAPISpec(path string) (*openAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &openAPISpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s: only OpenAPI 3.x is supported, got %q", path, spec.OpenAPI)
+	}
+	return spec, nil
+}
+
+// refName returns the component name of a local reference, e.g. Pet for
+// #/components/schemas/Pet.
+func refName(ref string, section string) (string, error) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %q, only %s... is supported here", ref, prefix)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+func (spec *openAPISpec) parameter(parameter *openAPIParameter) (*openAPIParameter, error) {
+	if parameter.Ref == "" {
+		return parameter, nil
+	}
+	name, err := refName(parameter.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := spec.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("parameter %q is not defined", parameter.Ref)
+	}
+	return resolved, nil
+}
+
+func (spec *openAPISpec) requestBody(requestBody *openAPIRequestBody) (*openAPIRequestBody, error) {
+	if requestBody.Ref == "" {
+		return requestBody, nil
+	}
+	name, err := refName(requestBody.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := spec.Components.RequestBodies[name]
+	if !ok {
+		return nil, fmt.Errorf("request body %q is not defined", requestBody.Ref)
+	}
+	return resolved, nil
+}
+
+func (spec *openAPISpec) response(response *openAPIResponse) (*openAPIResponse, error) {
+	if response.Ref == "" {
+		return response, nil
+	}
+	name, err := refName(response.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := spec.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("response %q is not defined", response.Ref)
+	}
+	return resolved, nil
+}
+
+// jsonSchema returns the schema of the JSON media type of content, if any.
+func jsonSchema(content map[string]*openAPIMediaType) *openAPISchema {
+	if mediaType, ok := content["application/json"]; ok && mediaType.Schema != nil {
+		return mediaType.Schema
+	}
+	for name, mediaType := range content {
+		if strings.HasPrefix(name, "application/") && strings.HasSuffix(name, "+json") && mediaType.Schema != nil {
+			return mediaType.Schema
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// partialsDir holds the files shared by templates: the built-in one at
+// template/_partials and one in each template, which overrides it.
+const partialsDir = "_partials"
+
+// partialSource is a _partials directory.
+type partialSource struct {
+	FS   fs.FS
+	Root string
+}
+
+// partials are the shared files of a template chain. Each .tmpl file is a
+// named template, called as {{template "gitignore/go" .}} for
+// _partials/gitignore/go.tmpl, along with the templates it defines; any file
+// or directory can also be included wholesale with the include list of
+// _template.yaml.
+type partials struct {
+	// sources are the _partials directories, the most specific first.
+	sources []partialSource
+	// set holds the named templates, cloned by every rendered file so the
+	// partials are parsed once per run.
+	set *template.Template
+}
+
+// newTemplateSet returns an empty set of templates with the functions and
+// options of project files.
+func newTemplateSet() *template.Template {
+	return template.New("partials").Funcs(templateFuncs).Option("missingkey=error")
+}
+
+// partials loads the _partials directories of the chain. Named templates of a
+// template override those of its parents and of the built-in directory.
+func (t *projectTemplate) partials() (*partials, error) {
+	return loadPartials(t.chain())
+}
+
+// loadPartials loads the built-in _partials directory and those of chain,
+// the root ancestor first. A built-in template of the chain brings the
+// built-in partials of its tree, those of another version of beginning for
+// diff --template-version.
+func loadPartials(chain []*projectTemplate) (*partials, error) {
+	builtins := builtinFS
+	for _, level := range chain {
+		if level.Source.Kind == sourceKindBuiltin {
+			builtins = level.FS
+		}
+	}
+	sources := []partialSource{{FS: builtins, Root: path.Join("template", partialsDir)}}
+	for _, level := range chain {
+		sources = append(sources, partialSource{FS: level.FS, Root: path.Join(level.Root, partialsDir)})
+	}
+
+	p := &partials{set: newTemplateSet()}
+	for _, source := range sources {
+		if _, err := fs.Stat(source.FS, source.Root); err != nil {
+			continue
+		}
+		p.sources = append([]partialSource{source}, p.sources...)
+		err := fs.WalkDir(source.FS, source.Root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(filePath) != ".tmpl" {
+				return err
+			}
+			content, err := fs.ReadFile(source.FS, filePath)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(filePath, source.Root+"/"), ".tmpl")
+			if _, err := p.set.New(name).Parse(string(content)); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// include renders the file or directory name of the partials into the same
+// path of the project.
+func (p *partials) include(name string, outputDir string, values Values) error {
+	for _, source := range p.sources {
+		filePath := path.Join(source.Root, path.Clean(name))
+		info, err := fs.Stat(source.FS, filePath)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			targetDir := filepath.Join(outputDir, filepath.FromSlash(name))
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				return err
+			}
+			return renderTree(source.FS, filePath, targetDir, values, p.set)
+		}
+		if err := os.MkdirAll(filepath.Join(outputDir, filepath.FromSlash(path.Dir(name))), 0755); err != nil {
+			return err
+		}
+		if err := renderTreeEntry(source.FS, source.Root, outputDir, values, p.set, filePath, fs.FileInfoToDirEntry(info)); err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no file %s in %s", name, partialsDir)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listOutput     string
+	listTags       []string
+	showComponents []string
+)
+
+// templateInfo is a template as listed by list -o json.
+type templateInfo struct {
+	Template    string             `json:"template"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Version     string             `json:"version,omitempty"`
+	Maintainer  string             `json:"maintainer,omitempty"`
+	GoVersion   string             `json:"goVersion"`
+	Tags        []string           `json:"tags"`
+	Extends     string             `json:"extends,omitempty"`
+	Components  []string           `json:"components"`
+	Variables   []templateVariable `json:"variables"`
+}
+
+func newTemplateInfo(ref string, t *projectTemplate) templateInfo {
+	info := templateInfo{
+		Template:    ref,
+		Name:        t.name(),
+		Description: strings.TrimSpace(t.Manifest.Description),
+		Version:     t.Manifest.Version,
+		Maintainer:  t.Manifest.Maintainer,
+		GoVersion:   t.goVersion(),
+		Tags:        t.Manifest.Tags,
+		Extends:     t.Manifest.Extends,
+		Components:  t.components(),
+		Variables:   t.variables(),
+	}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+	if info.Components == nil {
+		info.Components = []string{}
+	}
+	if info.Variables == nil {
+		info.Variables = []templateVariable{}
+	}
+	return info
+}
+
+func listTemplates(cmd *cobra.Command, args []string) {
+	if listOutput != "table" && listOutput != "json" {
+		fmt.Printf("❌ Unknown output format %q, expected table or json\n", listOutput)
+		os.Exit(1)
+	}
+	refs := args
+	if len(refs) == 0 {
+		refs = builtinTemplates()
+	}
+
+	var infos []templateInfo
+	for _, ref := range refs {
+		t, err := resolveTemplate(ref)
+		if err != nil {
+			fmt.Printf("❌ Error listing templates: %v\n", err)
+			os.Exit(1)
+		}
+		if t.hasTags(listTags) {
+			infos = append(infos, newTemplateInfo(ref, t))
+		}
+	}
+
+	if listOutput == "json" {
+		if infos == nil {
+			infos = []templateInfo{}
+		}
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		check(encoder.Encode(infos))
+		return
+	}
+	if len(infos) == 0 {
+		fmt.Printf("No template has the tags %s\n", strings.Join(listTags, ", "))
+		return
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEMPLATE\tVERSION\tGO\tTAGS\tDESCRIPTION")
+	for _, info := range infos {
+		summary, _, _ := strings.Cut(info.Description, "\n")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Template, orDash(info.Version), info.GoVersion, orDash(strings.Join(info.Tags, ",")), summary)
+	}
+	check(w.Flush())
+	fmt.Println("\nUse 'beginning show <template>' for the variables, components and files of a template")
+}
+
+func runShow(cmd *cobra.Command, args []string) {
+	ref := args[0]
+	t, err := resolveTemplate(ref)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	info := newTemplateInfo(ref, t)
+
+	title := info.Name
+	if info.Version != "" {
+		title += " " + info.Version
+	}
+	fmt.Println(title)
+	if info.Description != "" {
+		fmt.Printf("\n%s\n", info.Description)
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Template:\t%s\n", ref)
+	if info.Extends != "" {
+		fmt.Fprintf(w, "Extends:\t%s\n", info.Extends)
+	}
+	if info.Maintainer != "" {
+		fmt.Fprintf(w, "Maintainer:\t%s\n", info.Maintainer)
+	}
+	fmt.Fprintf(w, "Minimum Go:\t%s\n", info.GoVersion)
+	if len(info.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(info.Tags, ", "))
+	}
+	check(w.Flush())
+
+	if len(info.Variables) > 0 {
+		fmt.Println("\nVariables (--set Name=value):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, variable := range info.Variables {
+			value := fmt.Sprintf("default %q", variable.Default)
+			if variable.Required {
+				value = "required"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", variable.Name, value, variable.Description)
+		}
+		check(w.Flush())
+	}
+
+	if len(info.Components) > 0 {
+		fmt.Println("\nComponents (--with name):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, component := range info.Components {
+			fmt.Fprintf(w, "  %s\t%s\n", component, t.componentDescription(component))
+		}
+		check(w.Flush())
+	}
+
+	if hooks := t.postHooks(); len(hooks) > 0 {
+		fmt.Println("\nPost hooks:")
+		for _, hook := range hooks {
+			fmt.Printf("  %s\n", hook)
+		}
+	}
+
+	values, err := sampleValues(t, showComponents)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	outputDir, err := os.MkdirTemp("", "beginning-show-")
+	check(err)
+	defer os.RemoveAll(outputDir)
+	if _, err := t.render(outputDir, values); err != nil {
+		fmt.Printf("❌ Error rendering template: %v\n", err)
+		os.Exit(1)
+	}
+	files := "\nFiles of a project named example"
+	if len(showComponents) > 0 {
+		files += " with " + strings.Join(showComponents, ", ")
+	}
+	fmt.Println(files + ":")
+	fmt.Println("example/")
+	check(printTree(outputDir, ""))
+}
+
+// printTree prints the entries of dir as a tree, directories first.
+func printTree(dir string, indent string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir() && !entries[j].IsDir()
+	})
+	for i, entry := range entries {
+		branch, next := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, next = "└── ", "    "
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		fmt.Println(indent + branch + name)
+		if entry.IsDir() {
+			if err := printTree(filepath.Join(dir, entry.Name()), indent+next); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// completeTemplates completes the built-in templates with their summary.
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, ref := range builtinTemplates() {
+		t, err := resolveTemplate(ref)
+		if err != nil || t.summary() == "" {
+			completions = append(completions, ref)
+			continue
+		}
+		completions = append(completions, ref+"\t"+t.summary())
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateArg completes the template argument of show.
+func completeTemplateArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTemplates(cmd, args, toComplete)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Kinds of template sources. Built-in templates are no source of the user
+// configuration, the kind only records where a template was found.
+const (
+	sourceKindDir     = "dir"
+	sourceKindGit     = "git"
+	sourceKindModule  = "module"
+	sourceKindBuiltin = "builtin"
+)
+
+var (
+	reSourceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// reModulePath matches module paths, whose first element has a dot,
+	// with an optional version: github.com/ourorg/templates@v1.2.0
+	reModulePath = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+(/[^@\s]+)*(@\S+)?$`)
+)
+
+var sourceKind string
+
+// templateSource is a directory, git repository or Go module of templates,
+// one per subdirectory.
+type templateSource struct {
+	Kind     string `yaml:"kind"`
+	Location string `yaml:"location"`
+}
+
+// newTemplateSource returns the source at location, of the given kind or,
+// when empty, of the kind location looks like.
+func newTemplateSource(location string, kind string) (templateSource, error) {
+	if kind == "" {
+		_, _, _, git := parseGitTemplateRef(location)
+		expanded, err := expandHome(location)
+		switch {
+		case git:
+			kind = sourceKindGit
+		case err == nil && isDir(expanded):
+			kind = sourceKindDir
+		case reModulePath.MatchString(location):
+			kind = sourceKindModule
+		default:
+			return templateSource{}, fmt.Errorf("%s is neither a directory, a git repository nor a module path", location)
+		}
+	}
+
+	switch kind {
+	case sourceKindDir:
+		dir, err := expandHome(location)
+		if err != nil {
+			return templateSource{}, err
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			return templateSource{}, err
+		}
+		if !isDir(dir) {
+			return templateSource{}, fmt.Errorf("directory %s not found", dir)
+		}
+		location = dir
+	case sourceKindGit:
+		location = strings.TrimPrefix(location, "git+")
+	case sourceKindModule:
+		if !reModulePath.MatchString(location) {
+			return templateSource{}, fmt.Errorf("%s is not a module path", location)
+		}
+	default:
+		return templateSource{}, fmt.Errorf("unknown kind %q, expected %s, %s or %s", kind, sourceKindDir, sourceKindGit, sourceKindModule)
+	}
+	return templateSource{Kind: kind, Location: location}, nil
+}
+
+// checkout returns the directory of the source, fetching git repositories
+// and modules when they are not in the cache yet or refresh is set.
+func (s templateSource) checkout(refresh bool) (string, error) {
+	switch s.Kind {
+	case sourceKindDir:
+		if !isDir(s.Location) {
+			return "", fmt.Errorf("directory %s not found", s.Location)
+		}
+		return s.Location, nil
+	case sourceKindGit:
+		url, rev, subdir, _ := parseGitTemplateRef("git+" + s.Location)
+		dir, err := fetchGitTemplate(url, rev, refresh)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, filepath.FromSlash(subdir)), nil
+	case sourceKindModule:
+		return downloadModule(s.Location)
+	}
+	return "", fmt.Errorf("unknown kind %q", s.Kind)
+}
+
+// cachedDir returns the directory of the source without fetching it, "" when
+// it is not in the cache.
+func (s templateSource) cachedDir() string {
+	switch s.Kind {
+	case sourceKindDir:
+		return s.Location
+	case sourceKindGit:
+		url, rev, subdir, _ := parseGitTemplateRef("git+" + s.Location)
+		dir, err := gitTemplateCacheDir(url, rev)
+		if err != nil || !isDir(filepath.Join(dir, ".git")) {
+			return ""
+		}
+		return filepath.Join(dir, filepath.FromSlash(subdir))
+	}
+	return ""
+}
+
+// downloadModule downloads the module path@version, the latest version when
+// location has none, to the module cache and returns its directory.
+func downloadModule(location string) (string, error) {
+	if !strings.Contains(location, "@") {
+		location += "@latest"
+	}
+	cmd := exec.Command("go", "mod", "download", "-json", location)
+	// Outside of any module, so the go.mod of the working directory does not
+	// get in the way.
+	cmd.Dir = os.TempDir()
+	output, err := cmd.Output()
+	var module struct {
+		Dir     string
+		Version string
+		Error   string
+	}
+	if jsonErr := json.Unmarshal(output, &module); jsonErr != nil {
+		if err != nil {
+			return "", fmt.Errorf("go mod download %s: %w", location, err)
+		}
+		return "", fmt.Errorf("go mod download %s: %w", location, jsonErr)
+	}
+	if module.Error != "" {
+		return "", fmt.Errorf("go mod download %s: %s", location, module.Error)
+	}
+	return module.Dir, nil
+}
+
+// sourceTemplates lists the templates of a source directory, its
+// subdirectories. A source without any is a template itself.
+func sourceTemplates(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(entry.Name(), "_") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// locateRegistryTemplate finds a template of a source of the user
+// configuration: ourorg/grpc-service is the grpc-service directory of the
+// source ourorg.
+func locateRegistryTemplate(cfg *userConfig, ref string) (*projectTemplate, bool, error) {
+	name, subdir, _ := strings.Cut(ref, "/")
+	source, ok := cfg.Sources[name]
+	if !ok {
+		return nil, false, nil
+	}
+	checkout, err := source.checkout(false)
+	if err != nil {
+		return nil, true, fmt.Errorf("source %s: %w", name, err)
+	}
+	dir := filepath.Join(checkout, filepath.FromSlash(subdir))
+	if !isDir(dir) {
+		return nil, true, fmt.Errorf("source %s has no template %s, it has: %s", name, subdir, strings.Join(sourceTemplates(checkout), ", "))
+	}
+	id := source.Kind + ":" + source.Location
+	if subdir != "" {
+		id += "//" + subdir
+	}
+	return &projectTemplate{
+		Name: ref, ID: id, Dir: dir, FS: os.DirFS(dir), Root: ".",
+		Source: source, Path: subdir,
+	}, true, nil
+}
+
+func runSourceAdd(cmd *cobra.Command, args []string) {
+	name, location := args[0], args[1]
+	if !reSourceName.MatchString(name) {
+		fmt.Printf("❌ Invalid source name %q, use letters, digits, '.', '_' and '-'\n", name)
+		os.Exit(1)
+	}
+	for _, builtin := range builtinTemplates() {
+		if name == builtin {
+			fmt.Printf("❌ %s is a built-in template, choose another name\n", name)
+			os.Exit(1)
+		}
+	}
+	cfg := mustLoadUserConfig()
+	if _, ok := cfg.Sources[name]; ok {
+		fmt.Printf("❌ Source %s already exists, remove it first\n", name)
+		os.Exit(1)
+	}
+
+	source, err := newTemplateSource(location, sourceKind)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	dir, err := source.checkout(true)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Sources == nil {
+		cfg.Sources = map[string]templateSource{}
+	}
+	cfg.Sources[name] = source
+	check(cfg.save())
+	fmt.Printf("✅ Added %s source %s: %s\n", source.Kind, name, source.Location)
+	printSourceTemplates(name, dir)
+}
+
+func runSourceRemove(cmd *cobra.Command, args []string) {
+	cfg := mustLoadUserConfig()
+	name := args[0]
+	if _, ok := cfg.Sources[name]; !ok {
+		fmt.Printf("❌ Unknown source %s\n", name)
+		os.Exit(1)
+	}
+	delete(cfg.Sources, name)
+	check(cfg.save())
+	fmt.Printf("✅ Removed source %s\n", name)
+	for alias, ref := range cfg.Aliases {
+		if ref == name || strings.HasPrefix(ref, name+"/") {
+			fmt.Printf("⚠️  Alias %s still points to %s\n", alias, ref)
+		}
+	}
+}
+
+func runSourceList(cmd *cobra.Command, args []string) {
+	cfg := mustLoadUserConfig()
+	if len(cfg.Sources) == 0 && len(cfg.Aliases) == 0 {
+		fmt.Println("No template sources, add one with 'beginning source add <name> <location>'")
+		return
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	if len(cfg.Sources) > 0 {
+		fmt.Fprintln(w, "SOURCE\tKIND\tLOCATION")
+		for _, name := range sortedKeys(cfg.Sources) {
+			source := cfg.Sources[name]
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, source.Kind, source.Location)
+		}
+	}
+	if len(cfg.Aliases) > 0 {
+		if len(cfg.Sources) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "ALIAS\tTEMPLATE")
+		for _, alias := range sortedKeys(cfg.Aliases) {
+			fmt.Fprintf(w, "%s\t%s\n", alias, cfg.Aliases[alias])
+		}
+	}
+	check(w.Flush())
+}
+
+func runSourceRefresh(cmd *cobra.Command, args []string) {
+	cfg := mustLoadUserConfig()
+	names := args
+	if len(names) == 0 {
+		names = sortedKeys(cfg.Sources)
+	}
+	failed := false
+	for _, name := range names {
+		source, ok := cfg.Sources[name]
+		if !ok {
+			fmt.Printf("❌ Unknown source %s\n", name)
+			failed = true
+			continue
+		}
+		dir, err := source.checkout(true)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("✅ Refreshed %s\n", name)
+		printSourceTemplates(name, dir)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func runSourceAlias(cmd *cobra.Command, args []string) {
+	alias, ref := args[0], args[1]
+	if !reSourceName.MatchString(alias) {
+		fmt.Printf("❌ Invalid alias %q, use letters, digits, '.', '_' and '-'\n", alias)
+		os.Exit(1)
+	}
+	cfg := mustLoadUserConfig()
+	for _, builtin := range builtinTemplates() {
+		if alias == builtin {
+			fmt.Printf("❌ %s is a built-in template, choose another alias\n", alias)
+			os.Exit(1)
+		}
+	}
+	if _, ok := cfg.Sources[alias]; ok {
+		fmt.Printf("❌ %s is a source, choose another alias\n", alias)
+		os.Exit(1)
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = map[string]string{}
+	}
+	cfg.Aliases[alias] = ref
+	check(cfg.save())
+	if _, err := resolveTemplate(alias); err != nil {
+		fmt.Printf("⚠️  %s does not resolve yet: %v\n", ref, err)
+	}
+	fmt.Printf("✅ %s is now an alias of %s\n", alias, ref)
+}
+
+func runSourceUnalias(cmd *cobra.Command, args []string) {
+	cfg := mustLoadUserConfig()
+	if _, ok := cfg.Aliases[args[0]]; !ok {
+		fmt.Printf("❌ Unknown alias %s\n", args[0])
+		os.Exit(1)
+	}
+	delete(cfg.Aliases, args[0])
+	check(cfg.save())
+	fmt.Printf("✅ Removed alias %s\n", args[0])
+}
+
+func printSourceTemplates(name string, dir string) {
+	templates := sourceTemplates(dir)
+	if len(templates) == 0 {
+		fmt.Printf("   use it as -t %s\n", name)
+		return
+	}
+	for _, template := range templates {
+		fmt.Printf("   -t %s/%s\n", name, template)
+	}
+}
+
+// registryCompletions completes the aliases and the templates of the cached
+// sources of the user configuration.
+func registryCompletions() []string {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, alias := range sortedKeys(cfg.Aliases) {
+		completions = append(completions, alias+"\talias of "+cfg.Aliases[alias])
+	}
+	for _, name := range sortedKeys(cfg.Sources) {
+		source := cfg.Sources[name]
+		dir := source.cachedDir()
+		if dir == "" {
+			completions = append(completions, name+"/\t"+source.Kind+" source "+source.Location)
+			continue
+		}
+		for _, template := range sourceTemplates(dir) {
+			completions = append(completions, name+"/"+template+"\t"+name+" source")
+		}
+	}
+	return completions
+}
+
+func completeSourceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sortedKeys(cfg.Sources), cobra.ShellCompDirectiveNoFileComp
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
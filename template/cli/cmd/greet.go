@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type greeting struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+type greetings []greeting
+
+func (g greetings) Header() []string { return []string{"name", "message"} }
+
+func (g greetings) Rows() [][]string {
+	rows := make([][]string, 0, len(g))
+	for _, greeting := range g {
+		rows = append(rows, []string{greeting.Name, greeting.Message})
+	}
+	return rows
+}
+
+// newGreetCommand is an example command: it reads the configuration, takes
+// arguments and a flag, and prints its result with the selected format.
+func newGreetCommand(opts *options) *cobra.Command {
+	var shout bool
+	cmd := &cobra.Command{
+		Use:   "greet [name...]",
+		Short: "Greet people",
+		Long:  "Greet each name with the greeting of the configuration.",
+		Example: `  greet Alice Bob
+  greet --shout --output json Alice`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.Config()
+			if err != nil {
+				return err
+			}
+			printer, err := opts.Printer(cmd)
+			if err != nil {
+				return err
+			}
+
+			result := make(greetings, 0, len(args))
+			for _, name := range args {
+				message := cfg.Greeting + ", " + name + "!"
+				if shout {
+					message = strings.ToUpper(message)
+				}
+				result = append(result, greeting{Name: name, Message: message})
+			}
+			return printer.Print(result)
+		},
+	}
+	cmd.Flags().BoolVar(&shout, "shout", false, "greet in upper case")
+	return cmd
+}
@@ -0,0 +1,38 @@
+package cmd_test
+
+import "testing"
+
+func TestGreetTable(t *testing.T) {
+	newHarness(t).Run("greet", "Alice", "Bob").
+		assertOK(t).
+		assertStdout(t, "NAME   MESSAGE\nAlice  Hello, Alice!\nBob    Hello, Bob!\n")
+}
+
+func TestGreetJSON(t *testing.T) {
+	newHarness(t).Run("greet", "--shout", "-o", "json", "Alice").
+		assertOK(t).
+		assertStdout(t, `[
+  {
+    "name": "Alice",
+    "message": "HELLO, ALICE!"
+  }
+]
+`)
+}
+
+func TestGreetConfig(t *testing.T) {
+	h := newHarness(t)
+	h.WriteConfig("greeting: Hi\noutput: json\n")
+	h.Run("greet", "Alice").
+		assertOK(t).
+		assertStdout(t, "[\n  {\n    \"name\": \"Alice\",\n    \"message\": \"Hi, Alice!\"\n  }\n]\n")
+
+	// --output wins over the config file.
+	h.Run("greet", "-o", "table", "Alice").
+		assertOK(t).
+		assertStdout(t, "NAME   MESSAGE\nAlice  Hi, Alice!\n")
+}
+
+func TestGreetRequiresName(t *testing.T) {
+	newHarness(t).Run("greet").assertError(t, "requires at least 1 arg")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// Set at build time by bin/build.sh:
+//
+//	go build -ldflags "-X <module>/cmd.Version=v1.2.3 -X <module>/cmd.Commit=abc1234 -X <module>/cmd.Date=2024-01-01T00:00:00Z"
+var (
+	Version = "dev"
+	Commit  = "none"
+	Date    = "unknown"
+)
+
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+func (v versionInfo) Header() []string {
+	return []string{"version", "commit", "date", "go", "platform"}
+}
+
+func (v versionInfo) Rows() [][]string {
+	return [][]string{{v.Version, v.Commit, v.Date, v.GoVersion, v.Platform}}
+}
+
+func newVersionCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and build date",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer, err := opts.Printer(cmd)
+			if err != nil {
+				return err
+			}
+			return printer.Print(versionInfo{
+				Version:   Version,
+				Commit:    Commit,
+				Date:      Date,
+				GoVersion: runtime.Version(),
+				Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+			})
+		},
+	}
+}
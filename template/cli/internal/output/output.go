@@ -0,0 +1,62 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is how a command prints its result.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+)
+
+// Formats lists the accepted values of --output.
+var Formats = []string{string(FormatTable), string(FormatJSON)}
+
+// ParseFormat validates the value of --output.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatTable, FormatJSON:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("unknown output format %q, use %s", value, strings.Join(Formats, " or "))
+}
+
+// Table is a result printable as rows. The JSON output encodes the value
+// itself, so its fields need json tags.
+type Table interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// Printer writes results in the selected format.
+type Printer struct {
+	w      io.Writer
+	format Format
+}
+
+func NewPrinter(w io.Writer, format Format) *Printer {
+	return &Printer{w: w, format: format}
+}
+
+// Print writes v as indented JSON or as a table with aligned columns.
+func (p *Printer) Print(v Table) error {
+	if p.format == FormatJSON {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(v.Header(), "\t")))
+	for _, row := range v.Rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,30 @@
+#!/bin/bash
+# Run the tests with the race detector and report the coverage
+#
+#   ./bin/cover.sh          # Coverage per function in the terminal
+#   ./bin/cover.sh --html   # Also open the annotated source in a browser
+
+source "$(dirname "$0")"/utils.sh
+
+if ! commandExist go;
+then
+  echo 'please install golang'
+  exit 1
+fi
+
+project_dir="$(cd -- "$(dirname -- "$0")/.." &>/dev/null && pwd -P)"
+
+cd $project_dir
+
+# The race detector needs cgo
+if ! CGO_ENABLED=1 go test -race -covermode=atomic -coverprofile=coverage.out ./...;
+then
+  exit 1
+fi
+
+go tool cover -func=coverage.out
+
+if [ "$1" = "--html" ];
+then
+  go tool cover -html=coverage.out
+fi
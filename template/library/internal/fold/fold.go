@@ -0,0 +1,52 @@
+// Package fold spells letters in lower-case ASCII when they have a common
+// transliteration, like 'É' as "e" or 'ß' as "ss".
+package fold
+
+import "unicode"
+
+// latin lists the lower-case Latin letters of each ASCII spelling.
+var latin = map[string]string{
+	"a":  "àáâãäåāăą",
+	"ae": "æ",
+	"c":  "çćĉċč",
+	"d":  "ďđð",
+	"e":  "èéêëēĕėęě",
+	"g":  "ĝğġģ",
+	"h":  "ĥħ",
+	"i":  "ìíîïĩīĭįı",
+	"j":  "ĵ",
+	"k":  "ķ",
+	"l":  "ĺļľŀł",
+	"n":  "ñńņňŉ",
+	"o":  "òóôõöøōŏő",
+	"oe": "œ",
+	"r":  "ŕŗř",
+	"s":  "śŝşš",
+	"ss": "ß",
+	"t":  "ţťŧ",
+	"th": "þ",
+	"u":  "ùúûüũūŭůűų",
+	"w":  "ŵ",
+	"y":  "ýÿŷ",
+	"z":  "źżž",
+}
+
+var table = map[rune]string{}
+
+func init() {
+	for spelling, letters := range latin {
+		for _, r := range letters {
+			table[r] = spelling
+		}
+	}
+}
+
+// Rune returns the ASCII spelling of the lower case of r when it has one,
+// the lower case of r otherwise.
+func Rune(r rune) string {
+	r = unicode.ToLower(r)
+	if spelling, ok := table[r]; ok {
+		return spelling
+	}
+	return string(r)
+}
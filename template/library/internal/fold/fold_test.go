@@ -0,0 +1,33 @@
+package fold
+
+import "testing"
+
+func TestRune(t *testing.T) {
+	tests := map[rune]string{
+		'a': "a",
+		'Z': "z",
+		'7': "7",
+		'É': "e",
+		'ç': "c",
+		'ß': "ss",
+		'Æ': "ae",
+		'Ø': "o",
+		'Ж': "ж",
+		'京': "京",
+	}
+	for r, want := range tests {
+		if got := Rune(r); got != want {
+			t.Errorf("Rune(%q) = %q, want %q", r, got, want)
+		}
+	}
+}
+
+func TestTableIsLowerCase(t *testing.T) {
+	for spelling, letters := range latin {
+		for _, r := range letters {
+			if got := Rune(r); got != spelling {
+				t.Errorf("Rune(%q) = %q, want %q", r, got, spelling)
+			}
+		}
+	}
+}
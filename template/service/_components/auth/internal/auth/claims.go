@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type claimsKey struct{}
+
+// Claims are the verified claims of a request's bearer token.
+type Claims struct {
+	Subject string
+	Roles   []string
+	Scopes  []string
+	// Raw holds every claim of the token.
+	Raw jwt.MapClaims
+}
+
+func (claims *Claims) HasRole(role string) bool {
+	return slices.Contains(claims.Roles, role)
+}
+
+func (claims *Claims) HasScope(scope string) bool {
+	return slices.Contains(claims.Scopes, scope)
+}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the authenticated caller, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// lookup resolves a dotted claim path such as realm_access.roles.
+func lookup(claims jwt.MapClaims, path string) any {
+	var value any = map[string]any(claims)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// stringList accepts both a JSON array and a space separated string, the two
+// shapes used for roles and scopes ("scp" vs "scope").
+func stringList(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
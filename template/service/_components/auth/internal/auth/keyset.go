@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval limits how often an unknown kid triggers a JWKS fetch.
+const minRefreshInterval = 10 * time.Second
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+// KeySet resolves the verification key of a token from its kid header.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// StaticKeySet holds keys that never change: an HS256 secret, a PEM public
+// key or a JWKS file.
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+func NewSecretKeySet(secret string) *StaticKeySet {
+	return &StaticKeySet{keys: map[string]crypto.PublicKey{"": []byte(secret)}}
+}
+
+// NewPublicKeySet reads an RSA or Ed25519 public key (or certificate) in PEM.
+func NewPublicKeySet(path string) (*StaticKeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	var key crypto.PublicKey
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = certificate.PublicKey
+		}
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &StaticKeySet{keys: map[string]crypto.PublicKey{"": key}}, nil
+}
+
+func NewJWKSFileKeySet(path string) (*StaticKeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+func (staticKeySet *StaticKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	return findKey(staticKeySet.keys, kid)
+}
+
+// RemoteKeySet caches the keys served by a JWKS endpoint. The keys are
+// refetched once they are older than the refresh interval, or early when a
+// token names an unknown kid (key rotation). Concurrent lookups share one
+// fetch, made without holding the lock, and a failed refresh keeps serving
+// the cached keys.
+type RemoteKeySet struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	logger   *slog.Logger
+	group    singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewRemoteKeySet(url string, interval time.Duration, logger *slog.Logger) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:      url,
+		interval: interval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+	}
+}
+
+func (remoteKeySet *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	remoteKeySet.mu.Lock()
+	_, known := remoteKeySet.keys[kid]
+	stale := time.Since(remoteKeySet.fetchedAt) > remoteKeySet.interval
+	remoteKeySet.mu.Unlock()
+	if stale || !known {
+		// Lookups arriving during a refresh wait for it instead of starting
+		// their own
+		remoteKeySet.group.Do("refresh", func() (any, error) {
+			remoteKeySet.refresh(ctx)
+			return nil, nil
+		})
+	}
+	remoteKeySet.mu.Lock()
+	defer remoteKeySet.mu.Unlock()
+	return findKey(remoteKeySet.keys, kid)
+}
+
+// refresh fetches the keys unless the last attempt is too recent and swaps
+// them in. The fetch outlives the cancellation of the request starting it
+// since other lookups wait for it.
+func (remoteKeySet *RemoteKeySet) refresh(ctx context.Context) {
+	remoteKeySet.mu.Lock()
+	now := time.Now()
+	if now.Sub(remoteKeySet.attemptedAt) <= minRefreshInterval {
+		remoteKeySet.mu.Unlock()
+		return
+	}
+	remoteKeySet.attemptedAt = now
+	remoteKeySet.mu.Unlock()
+
+	keys, err := remoteKeySet.fetch(context.WithoutCancel(ctx))
+	if err != nil {
+		remoteKeySet.logger.WarnContext(ctx, "refresh JWKS failed", slog.String("url", remoteKeySet.url), slog.Any("error", err))
+		return
+	}
+	remoteKeySet.mu.Lock()
+	remoteKeySet.keys, remoteKeySet.fetchedAt = keys, now
+	remoteKeySet.mu.Unlock()
+}
+
+func (remoteKeySet *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, remoteKeySet.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := remoteKeySet.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(b)
+}
+
+// findKey returns the key named kid. Tokens without a kid are accepted when
+// the set holds a single key.
+func findKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	K   string `json:"k"`
+}
+
+// parseJWKS reads the RSA, Ed25519 and symmetric keys of a JWK set and skips
+// the encryption keys and key types it does not support.
+func parseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return decode(jwk.K)
+	default:
+		return nil, nil
+	}
+}
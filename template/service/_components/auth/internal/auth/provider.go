@@ -0,0 +1,8 @@
+package auth
+
+import "github.com/google/wire"
+
+var ProviderSetAuth = wire.NewSet(
+	NewKeySet,
+	NewVerifier,
+)
@@ -0,0 +1,31 @@
+package config
+
+type Auth struct {
+	// Algorithms accepted in the token header: HS256, RS256 and/or EdDSA.
+	Algorithms []string `json:"algorithms" yaml:"algorithms" env:"AUTH_ALGORITHMS" default:"HS256"`
+	// Key source, the first one set wins: JWKSURL, JWKSFile, PublicKeyFile, Secret.
+	JWKSURL             string `json:"jwksURL" yaml:"jwksURL" env:"AUTH_JWKS_URL"`
+	JWKSFile            string `json:"jwksFile" yaml:"jwksFile" env:"AUTH_JWKS_FILE"`
+	JWKSRefreshInterval string `json:"jwksRefreshInterval" yaml:"jwksRefreshInterval" env:"AUTH_JWKS_REFRESH_INTERVAL" default:"15m"`
+	PublicKeyFile       string `json:"publicKeyFile" yaml:"publicKeyFile" env:"AUTH_PUBLIC_KEY_FILE"`
+	Secret              string `json:"secret" yaml:"secret" env:"AUTH_SECRET" secret:"true"`
+	Issuer              string `json:"issuer" yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience            string `json:"audience" yaml:"audience" env:"AUTH_AUDIENCE"`
+	Leeway              string `json:"leeway" yaml:"leeway" env:"AUTH_LEEWAY" default:"30s"`
+	// RolesClaim may be a dotted path, e.g. realm_access.roles.
+	RolesClaim  string `json:"rolesClaim" yaml:"rolesClaim" env:"AUTH_ROLES_CLAIM" default:"roles"`
+	ScopesClaim string `json:"scopesClaim" yaml:"scopesClaim" env:"AUTH_SCOPES_CLAIM" default:"scope"`
+}
+
+func (auth Auth) validate(v *validator) {
+	v.check(len(auth.Algorithms) > 0, "auth.algorithms", "at least one algorithm is required")
+	for _, algorithm := range auth.Algorithms {
+		v.oneOf("auth.algorithms", algorithm, "HS256", "RS256", "EdDSA")
+	}
+	v.check(auth.JWKSURL != "" || auth.JWKSFile != "" || auth.PublicKeyFile != "" || auth.Secret != "",
+		"auth", "one of jwksURL, jwksFile, publicKeyFile or secret is required")
+	if auth.JWKSURL != "" {
+		v.duration("auth.jwksRefreshInterval", auth.JWKSRefreshInterval)
+	}
+	v.duration("auth.leeway", auth.Leeway)
+}
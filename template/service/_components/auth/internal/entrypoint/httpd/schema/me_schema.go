@@ -0,0 +1,7 @@
+package schema
+
+type MeResponse struct {
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+	Scopes  []string `json:"scopes"`
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestCache(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		clock := time.Unix(1700000000, 0)
+		cache := NewLRU[int, user]("users", 10, time.Minute).(*instrumentedCache[int, user])
+		cache.store.(*lruStore[int, user]).now = func() time.Time { return clock }
+		testCache(t, cache, func(d time.Duration) { clock = clock.Add(d) })
+	})
+	t.Run("redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		testCache(t, NewRedis[int, user](client, "test:", "users", time.Minute), server.FastForward)
+	})
+}
+
+// testCache runs the expiry and deletion of cache, whose clock advance moves.
+func testCache(t *testing.T, cache Cache[int, user], advance func(time.Duration)) {
+	ctx := context.Background()
+
+	if _, ok, err := cache.Get(ctx, 1); err != nil || ok {
+		t.Fatalf("empty cache: got ok=%v err=%v", ok, err)
+	}
+	if err := cache.Set(ctx, 1, user{ID: 1, Name: "ada"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set(ctx, 2, user{ID: 2, Name: "bob"}, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, err := cache.Get(ctx, 1); err != nil || !ok || value.Name != "ada" {
+		t.Fatalf("get: got %+v ok=%v err=%v", value, ok, err)
+	}
+
+	advance(30 * time.Second)
+	if _, ok, _ := cache.Get(ctx, 2); ok {
+		t.Fatal("entry with a 10s TTL is still cached after 30s")
+	}
+	if _, ok, _ := cache.Get(ctx, 1); !ok {
+		t.Fatal("entry with the default 1m TTL expired after 30s")
+	}
+
+	if err := cache.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get(ctx, 1); ok {
+		t.Fatal("deleted entry is still cached")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU[string, int]("numbers", 2, time.Minute)
+	cache.Set(ctx, "a", 1, 0)
+	cache.Set(ctx, "b", 2, 0)
+	cache.Get(ctx, "a")
+	cache.Set(ctx, "c", 3, 0)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := cache.Get(ctx, key); ok != want {
+			t.Errorf("%s cached: got %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU[int, user]("users", 10, time.Minute)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (user, error) {
+		loads.Add(1)
+		<-release
+		return user{ID: 1, Name: "ada"}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := cache.GetOrLoad(ctx, 1, 0, load); err != nil || value.Name != "ada" {
+				t.Errorf("GetOrLoad: got %+v, %v", value, err)
+			}
+		}()
+	}
+	// Let the callers pile up on the first load.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("concurrent misses loaded %d times, want 1", got)
+	}
+	if _, err := cache.GetOrLoad(ctx, 1, 0, load); err != nil || loads.Load() != 1 {
+		t.Fatalf("cached value was loaded again: %d loads, err %v", loads.Load(), err)
+	}
+
+	failure := errors.New("database is down")
+	_, err := cache.GetOrLoad(ctx, 2, 0, func(ctx context.Context) (user, error) {
+		return user{}, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("load error: got %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, 2); ok {
+		t.Fatal("failed load was cached")
+	}
+}
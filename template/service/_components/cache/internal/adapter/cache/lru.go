@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// lruStore keeps up to maxEntries values in process, evicting the least
+// recently used one when full. Expired entries are dropped when read.
+type lruStore[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[K]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+// NewLRU returns a Cache of up to maxEntries values held in process. Every
+// replica has its own, use NewRedis to share the entries.
+func NewLRU[K comparable, V any](name string, maxEntries int, defaultTTL time.Duration) Cache[K, V] {
+	return newInstrumentedCache[K, V](name, "memory", newLRUStore[K, V](maxEntries), defaultTTL)
+}
+
+func newLRUStore[K comparable, V any](maxEntries int) *lruStore[K, V] {
+	return &lruStore[K, V]{
+		maxEntries: maxEntries,
+		entries:    map[K]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (lru *lruStore[K, V]) get(ctx context.Context, key K) (V, bool, error) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	var zero V
+	element, ok := lru.entries[key]
+	if !ok {
+		return zero, false, nil
+	}
+	entry := element.Value.(*lruEntry[K, V])
+	if !lru.now().Before(entry.expires) {
+		lru.remove(element)
+		return zero, false, nil
+	}
+	lru.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (lru *lruStore[K, V]) set(ctx context.Context, key K, value V, ttl time.Duration) error {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	expires := lru.now().Add(ttl)
+	if element, ok := lru.entries[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value, entry.expires = value, expires
+		lru.order.MoveToFront(element)
+		return nil
+	}
+	lru.entries[key] = lru.order.PushFront(&lruEntry[K, V]{key: key, value: value, expires: expires})
+	for lru.maxEntries > 0 && lru.order.Len() > lru.maxEntries {
+		lru.remove(lru.order.Back())
+	}
+	return nil
+}
+
+func (lru *lruStore[K, V]) delete(ctx context.Context, key K) error {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	if element, ok := lru.entries[key]; ok {
+		lru.remove(element)
+	}
+	return nil
+}
+
+func (lru *lruStore[K, V]) remove(element *list.Element) {
+	lru.order.Remove(element)
+	delete(lru.entries, element.Value.(*lruEntry[K, V]).key)
+}
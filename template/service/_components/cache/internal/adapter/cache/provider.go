@@ -0,0 +1,8 @@
+package cache
+
+import "github.com/google/wire"
+
+var ProviderSetCache = wire.NewSet(
+	NewBackend,
+	NewChecker,
+)
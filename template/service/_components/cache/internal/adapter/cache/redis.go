@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore keeps JSON-encoded values under keyPrefix + name + ":" + key in
+// any server speaking the Redis protocol (Redis, Valkey, KeyDB, ...).
+type redisStore[K comparable, V any] struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedis returns a Cache shared by every replica through client. Values
+// are encoded as JSON, so V must round-trip through encoding/json.
+func NewRedis[K comparable, V any](client redis.UniversalClient, keyPrefix string, name string, defaultTTL time.Duration) Cache[K, V] {
+	store := &redisStore[K, V]{client: client, keyPrefix: keyPrefix + name + ":"}
+	return newInstrumentedCache[K, V](name, "redis", store, defaultTTL)
+}
+
+func (redisStore *redisStore[K, V]) key(key K) string {
+	return redisStore.keyPrefix + fmt.Sprint(key)
+}
+
+func (redisStore *redisStore[K, V]) get(ctx context.Context, key K) (V, bool, error) {
+	var value V
+	data, err := redisStore.client.Get(ctx, redisStore.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return value, false, nil
+	}
+	if err != nil {
+		return value, false, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("decode cached %s: %w", redisStore.key(key), err)
+	}
+	return value, true, nil
+}
+
+func (redisStore *redisStore[K, V]) set(ctx context.Context, key K, value V, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", redisStore.key(key), err)
+	}
+	return redisStore.client.Set(ctx, redisStore.key(key), data, ttl).Err()
+}
+
+func (redisStore *redisStore[K, V]) delete(ctx context.Context, key K) error {
+	return redisStore.client.Del(ctx, redisStore.key(key)).Err()
+}
@@ -0,0 +1,27 @@
+package config
+
+type Cache struct {
+	// Store holds the entries: memory (LRU per process) or redis (shared).
+	Store      string `json:"store" yaml:"store" env:"CACHE_STORE" default:"memory"`
+	DefaultTTL string `json:"defaultTTL" yaml:"defaultTTL" env:"CACHE_DEFAULT_TTL" default:"5m"`
+	// MaxEntries bounds each in-process cache, 0 for no bound.
+	MaxEntries int        `json:"maxEntries" yaml:"maxEntries" env:"CACHE_MAX_ENTRIES" default:"10000"`
+	Redis      CacheRedis `json:"redis" yaml:"redis"`
+}
+
+type CacheRedis struct {
+	Addr      string `json:"addr" yaml:"addr" env:"CACHE_REDIS_ADDR" default:"localhost:6379"`
+	Username  string `json:"username" yaml:"username" env:"CACHE_REDIS_USERNAME"`
+	Password  string `json:"password" yaml:"password" env:"CACHE_REDIS_PASSWORD" secret:"true"`
+	DB        int    `json:"db" yaml:"db" env:"CACHE_REDIS_DB" default:"0"`
+	KeyPrefix string `json:"keyPrefix" yaml:"keyPrefix" env:"CACHE_REDIS_KEY_PREFIX" default:"cache:"`
+}
+
+func (cache Cache) validate(v *validator) {
+	v.oneOf("cache.store", cache.Store, "memory", "redis")
+	v.duration("cache.defaultTTL", cache.DefaultTTL)
+	v.check(cache.MaxEntries >= 0, "cache.maxEntries", "must not be negative")
+	if cache.Store == "redis" {
+		v.required("cache.redis.addr", cache.Redis.Addr)
+	}
+}
@@ -0,0 +1,33 @@
+#!/bin/bash
+# Regenerate the Go stubs in api/gen from the protos in api/proto
+
+source "$(dirname "$0")"/utils.sh
+
+if ! commandExist go;
+then
+  echo 'please install golang'
+  exit 1
+fi
+
+project_dir="$(cd -- "$(dirname -- "$0")/.." &>/dev/null && pwd -P)"
+
+cd $project_dir
+
+export PATH=$PATH:$(go env GOPATH)/bin
+
+if ! commandExist buf;
+then
+  go install github.com/bufbuild/buf/cmd/buf@latest
+fi
+
+if ! commandExist protoc-gen-go;
+then
+  go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+fi
+
+if ! commandExist protoc-gen-go-grpc;
+then
+  go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+fi
+
+buf lint && buf generate
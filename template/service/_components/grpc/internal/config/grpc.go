@@ -0,0 +1,10 @@
+package config
+
+type GRPC struct {
+	Addr       string `json:"addr" yaml:"addr" env:"GRPC_ADDR" default:"0.0.0.0:9090"`
+	Reflection bool   `json:"reflection" yaml:"reflection" env:"GRPC_REFLECTION" default:"false"`
+}
+
+func (grpc GRPC) validate(v *validator) {
+	v.required("grpc.addr", grpc.Addr)
+}
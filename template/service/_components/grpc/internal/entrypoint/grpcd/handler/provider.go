@@ -0,0 +1,7 @@
+package handler
+
+import "github.com/google/wire"
+
+var ProviderSetHandler = wire.NewSet(
+	NewPingHandler,
+)
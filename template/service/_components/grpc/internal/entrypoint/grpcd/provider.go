@@ -0,0 +1,9 @@
+package grpcd
+
+import "github.com/google/wire"
+
+var ProviderSetGRPCServer = wire.NewSet(
+	NewHealthServer,
+	NewGRPCServer,
+	NewServer,
+)
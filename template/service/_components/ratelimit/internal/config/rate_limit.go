@@ -0,0 +1,50 @@
+package config
+
+import "fmt"
+
+type RateLimit struct {
+	// Store keeps the token buckets: memory (per process) or redis (shared).
+	Store    string            `json:"store" yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+	Redis    RateLimitRedis    `json:"redis" yaml:"redis"`
+	Policies []RateLimitPolicy `json:"policies" yaml:"policies"`
+}
+
+type RateLimitRedis struct {
+	Addr      string `json:"addr" yaml:"addr" env:"RATE_LIMIT_REDIS_ADDR" default:"localhost:6379"`
+	Username  string `json:"username" yaml:"username" env:"RATE_LIMIT_REDIS_USERNAME"`
+	Password  string `json:"password" yaml:"password" env:"RATE_LIMIT_REDIS_PASSWORD" secret:"true"`
+	DB        int    `json:"db" yaml:"db" env:"RATE_LIMIT_REDIS_DB" default:"0"`
+	KeyPrefix string `json:"keyPrefix" yaml:"keyPrefix" env:"RATE_LIMIT_REDIS_KEY_PREFIX" default:"ratelimit:"`
+}
+
+// RateLimitPolicy allows Limit requests per Period, with bursts of up to Burst
+// requests, for each client of the matching routes.
+type RateLimitPolicy struct {
+	Name string `json:"name" yaml:"name"`
+	// Routes are gin route paths, optionally prefixed with a method, e.g.
+	// "GET /orders/:id". A path ending in /* matches the whole group.
+	Routes []string `json:"routes" yaml:"routes"`
+	// Key identifies the client: ip, subject (authenticated caller) or api_key.
+	Key          string `json:"key" yaml:"key" default:"ip"`
+	APIKeyHeader string `json:"apiKeyHeader" yaml:"apiKeyHeader" default:"X-API-Key"`
+	Limit        int    `json:"limit" yaml:"limit"`
+	Period       string `json:"period" yaml:"period" default:"1m"`
+	Burst        int    `json:"burst" yaml:"burst"`
+}
+
+func (rateLimit RateLimit) validate(v *validator) {
+	v.oneOf("rateLimit.store", rateLimit.Store, "memory", "redis")
+	if rateLimit.Store == "redis" {
+		v.required("rateLimit.redis.addr", rateLimit.Redis.Addr)
+	}
+	for i, policy := range rateLimit.Policies {
+		name := fmt.Sprintf("rateLimit.policies[%d]", i)
+		v.check(len(policy.Routes) > 0, name+".routes", "at least one route is required")
+		v.check(policy.Limit > 0, name+".limit", "must be positive")
+		v.check(policy.Burst >= 0, name+".burst", "must not be negative")
+		v.duration(name+".period", policy.Period)
+		if policy.Key != "" {
+			v.oneOf(name+".key", policy.Key, "ip", "subject", "api_key")
+		}
+	}
+}
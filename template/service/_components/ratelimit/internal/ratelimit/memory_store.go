@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped from memory.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps the buckets in process. Every replica limits on its own,
+// so use the RedisStore when a service runs more than one instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	limits  map[string]Limit
+	swept   time.Time
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		limits:  map[string]Limit{},
+		swept:   time.Now(),
+		now:     time.Now,
+	}
+}
+
+func (memoryStore *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	memoryStore.mu.Lock()
+	defer memoryStore.mu.Unlock()
+	now := memoryStore.now()
+	b, ok := memoryStore.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		memoryStore.buckets[key], memoryStore.limits[key] = b, limit
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	memoryStore.sweep(now)
+	return newResult(allowed, b.tokens, limit), nil
+}
+
+// sweep drops the buckets that have refilled, as they hold no state.
+func (memoryStore *MemoryStore) sweep(now time.Time) {
+	if now.Sub(memoryStore.swept) < sweepInterval {
+		return
+	}
+	memoryStore.swept = now
+	for key, b := range memoryStore.buckets {
+		limit := memoryStore.limits[key]
+		if b.tokens+now.Sub(b.updated).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(memoryStore.buckets, key)
+			delete(memoryStore.limits, key)
+		}
+	}
+}
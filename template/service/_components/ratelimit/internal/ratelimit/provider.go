@@ -0,0 +1,8 @@
+package ratelimit
+
+import "github.com/google/wire"
+
+var ProviderSetRateLimit = wire.NewSet(
+	NewStore,
+	NewLimiter,
+)
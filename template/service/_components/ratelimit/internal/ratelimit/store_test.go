@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		clock := time.Unix(1700000000, 0)
+		store := NewMemoryStore()
+		store.now = func() time.Time { return clock }
+		testTake(t, store, &clock)
+	})
+	t.Run("redis", func(t *testing.T) {
+		clock := time.Unix(1700000000, 0)
+		server := miniredis.RunT(t)
+		store := NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:")
+		store.now = func() time.Time { return clock }
+		testTake(t, store, &clock)
+	})
+}
+
+// testTake runs the token bucket of store, whose time is clock.
+func testTake(t *testing.T, store Store, clock *time.Time) {
+	ctx := context.Background()
+	// 2 requests per second, bursts of 3.
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "client", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != i || result.Limit != 3 {
+			t.Fatalf("take %d: got %+v", 3-i, result)
+		}
+	}
+	result, err := store.Take(ctx, "client", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("over limit: got %+v", result)
+	}
+
+	other, err := store.Take(ctx, "other", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Allowed {
+		t.Fatalf("other client: got %+v", other)
+	}
+
+	*clock = clock.Add(500 * time.Millisecond)
+	result, err = store.Take(ctx, "client", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 || result.Reset != 1500*time.Millisecond {
+		t.Fatalf("after refill: got %+v", result)
+	}
+}
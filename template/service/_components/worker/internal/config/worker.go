@@ -0,0 +1,21 @@
+package config
+
+type Worker struct {
+	Queue        string `json:"queue" yaml:"queue" env:"WORKER_QUEUE" default:"database"`
+	Concurrency  int    `json:"concurrency" yaml:"concurrency" env:"WORKER_CONCURRENCY" default:"4"`
+	PollInterval string `json:"pollInterval" yaml:"pollInterval" env:"WORKER_POLL_INTERVAL" default:"1s"`
+	MaxAttempts  int    `json:"maxAttempts" yaml:"maxAttempts" env:"WORKER_MAX_ATTEMPTS" default:"5"`
+	BackoffBase  string `json:"backoffBase" yaml:"backoffBase" env:"WORKER_BACKOFF_BASE" default:"1s"`
+	BackoffMax   string `json:"backoffMax" yaml:"backoffMax" env:"WORKER_BACKOFF_MAX" default:"10m"`
+	LeaseTimeout string `json:"leaseTimeout" yaml:"leaseTimeout" env:"WORKER_LEASE_TIMEOUT" default:"5m"`
+}
+
+func (worker Worker) validate(v *validator) {
+	v.oneOf("worker.queue", worker.Queue, "database", "memory")
+	v.check(worker.Concurrency > 0, "worker.concurrency", "must be positive")
+	v.check(worker.MaxAttempts > 0, "worker.maxAttempts", "must be positive")
+	v.duration("worker.pollInterval", worker.PollInterval)
+	v.duration("worker.backoffBase", worker.BackoffBase)
+	v.duration("worker.backoffMax", worker.BackoffMax)
+	v.duration("worker.leaseTimeout", worker.LeaseTimeout)
+}
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns the delay before the next attempt: base doubled for every
+// attempt already made, capped at max, with ±20% jitter.
+func Backoff(attempts int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	jitter := time.Duration(rand.Int64N(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRecord is the row stored by DatabaseQueue.
+type JobRecord struct {
+	ID          string    `gorm:"primaryKey;size:32"`
+	Type        string    `gorm:"size:255;not null"`
+	Payload     []byte    `gorm:"not null"`
+	Status      JobStatus `gorm:"size:16;not null;index:idx_worker_jobs_due,priority:1"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null;default:0"`
+	RunAt       time.Time `gorm:"not null;index:idx_worker_jobs_due,priority:2"`
+	LockedAt    *time.Time
+	LastError   string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (JobRecord) TableName() string {
+	return "worker_jobs"
+}
+
+// DatabaseQueue stores jobs in the worker_jobs table. Jobs are claimed with
+// SELECT ... FOR UPDATE SKIP LOCKED so several workers can share the table,
+// and running jobs whose lease expired are claimed again.
+type DatabaseQueue struct {
+	db           *gorm.DB
+	leaseTimeout time.Duration
+}
+
+func NewDatabaseQueue(db *gorm.DB, leaseTimeout time.Duration) *DatabaseQueue {
+	return &DatabaseQueue{db: db, leaseTimeout: leaseTimeout}
+}
+
+func (databaseQueue *DatabaseQueue) Enqueue(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		job.ID = newJobID()
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	return databaseQueue.db.WithContext(ctx).Create(&JobRecord{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     job.Payload,
+		Status:      JobStatusPending,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+	}).Error
+}
+
+func (databaseQueue *DatabaseQueue) Dequeue(ctx context.Context) (*Job, error) {
+	var record JobRecord
+	err := databaseQueue.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", JobStatusPending, now).
+			Or("status = ? AND locked_at <= ?", JobStatusRunning, now.Add(-databaseQueue.leaseTimeout)).
+			Order("run_at").
+			Take(&record).Error
+		if err != nil {
+			return err
+		}
+		record.Status = JobStatusRunning
+		record.Attempts++
+		record.LockedAt = &now
+		return tx.Model(&record).Updates(map[string]any{
+			"status":    record.Status,
+			"attempts":  record.Attempts,
+			"locked_at": record.LockedAt,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Job{
+		ID:          record.ID,
+		Type:        record.Type,
+		Payload:     record.Payload,
+		Attempts:    record.Attempts,
+		MaxAttempts: record.MaxAttempts,
+		RunAt:       record.RunAt,
+		LastError:   record.LastError,
+	}, nil
+}
+
+func (databaseQueue *DatabaseQueue) Complete(ctx context.Context, job *Job) error {
+	return databaseQueue.db.WithContext(ctx).Delete(&JobRecord{ID: job.ID}).Error
+}
+
+func (databaseQueue *DatabaseQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	return databaseQueue.db.WithContext(ctx).Model(&JobRecord{ID: job.ID}).Updates(map[string]any{
+		"status":     JobStatusPending,
+		"run_at":     runAt,
+		"locked_at":  nil,
+		"last_error": cause.Error(),
+	}).Error
+}
+
+func (databaseQueue *DatabaseQueue) DeadLetter(ctx context.Context, job *Job, cause error) error {
+	return databaseQueue.db.WithContext(ctx).Model(&JobRecord{ID: job.ID}).Updates(map[string]any{
+		"status":     JobStatusDead,
+		"locked_at":  nil,
+		"last_error": cause.Error(),
+	}).Error
+}
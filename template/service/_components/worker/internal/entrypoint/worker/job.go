@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobStatusPending JobStatus = "pending"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDead    JobStatus = "dead"
+)
+
+type Job struct {
+	ID   string
+	Type string
+	// Payload is the JSON-encoded job argument.
+	Payload json.RawMessage
+	// Attempts counts how many times the job was dequeued, including the
+	// current one.
+	Attempts int
+	// MaxAttempts overrides config.Worker.MaxAttempts when greater than 0.
+	MaxAttempts int
+	RunAt       time.Time
+	LastError   string
+}
+
+// NewJob returns a job of the given type, due now, with payload encoded as JSON.
+func NewJob(jobType string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{
+		ID:      newJobID(),
+		Type:    jobType,
+		Payload: data,
+		RunAt:   time.Now(),
+	}, nil
+}
+
+// Decode unmarshals the payload into v.
+func (job *Job) Decode(v any) error {
+	return json.Unmarshal(job.Payload, v)
+}
+
+func newJobID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
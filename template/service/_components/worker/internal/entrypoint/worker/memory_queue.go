@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryQueue keeps jobs in process memory. Jobs are lost on restart, so it
+// is meant for tests and local development.
+type MemoryQueue struct {
+	mu          sync.Mutex
+	pending     []*Job
+	running     map[string]*Job
+	deadLetters []*Job
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{running: make(map[string]*Job)}
+}
+
+func (memoryQueue *MemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		job.ID = newJobID()
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	memoryQueue.pending = append(memoryQueue.pending, job)
+	return nil
+}
+
+func (memoryQueue *MemoryQueue) Dequeue(ctx context.Context) (*Job, error) {
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	now := time.Now()
+	next := -1
+	for i, job := range memoryQueue.pending {
+		if !job.RunAt.After(now) && (next < 0 || job.RunAt.Before(memoryQueue.pending[next].RunAt)) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return nil, nil
+	}
+	job := memoryQueue.pending[next]
+	memoryQueue.pending = append(memoryQueue.pending[:next], memoryQueue.pending[next+1:]...)
+	job.Attempts++
+	memoryQueue.running[job.ID] = job
+	return job, nil
+}
+
+func (memoryQueue *MemoryQueue) Complete(ctx context.Context, job *Job) error {
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	delete(memoryQueue.running, job.ID)
+	return nil
+}
+
+func (memoryQueue *MemoryQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	delete(memoryQueue.running, job.ID)
+	job.RunAt = runAt
+	job.LastError = cause.Error()
+	memoryQueue.pending = append(memoryQueue.pending, job)
+	return nil
+}
+
+func (memoryQueue *MemoryQueue) DeadLetter(ctx context.Context, job *Job, cause error) error {
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	delete(memoryQueue.running, job.ID)
+	job.LastError = cause.Error()
+	memoryQueue.deadLetters = append(memoryQueue.deadLetters, job)
+	return nil
+}
+
+// DeadLetters returns the jobs parked by DeadLetter.
+func (memoryQueue *MemoryQueue) DeadLetters() []*Job {
+	memoryQueue.mu.Lock()
+	defer memoryQueue.mu.Unlock()
+	return append([]*Job(nil), memoryQueue.deadLetters...)
+}
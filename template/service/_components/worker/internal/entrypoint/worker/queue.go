@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// Queue stores jobs until a worker is ready to run them.
+type Queue interface {
+	Enqueue(ctx context.Context, job *Job) error
+	// Dequeue claims the next due job and increments its Attempts. It returns
+	// nil when no job is due.
+	Dequeue(ctx context.Context) (*Job, error)
+	// Complete removes a job that succeeded.
+	Complete(ctx context.Context, job *Job) error
+	// Retry makes a failed job due again at runAt.
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error
+	// DeadLetter parks a job that ran out of attempts or has no handler.
+	DeadLetter(ctx context.Context, job *Job, cause error) error
+}
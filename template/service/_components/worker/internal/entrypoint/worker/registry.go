@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Handler runs one job. Returning an error schedules a retry.
+type Handler func(ctx context.Context, job *Job) error
+
+// Registry maps job types to their handler.
+type Registry struct {
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register adds the handler for jobType. It panics when jobType is already
+// registered.
+func (registry *Registry) Register(jobType string, handler Handler) {
+	if _, ok := registry.handlers[jobType]; ok {
+		panic(fmt.Sprintf("worker: handler for job type %q registered twice", jobType))
+	}
+	registry.handlers[jobType] = handler
+}
+
+func (registry *Registry) Handler(jobType string) (Handler, bool) {
+	handler, ok := registry.handlers[jobType]
+	return handler, ok
+}
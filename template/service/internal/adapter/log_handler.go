@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redactedValue = "[REDACTED]"
+
+// traceHandler adds the trace and span IDs of the record's context.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (handler *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record = record.Clone()
+		record.AddAttrs(
+			slog.String("trace_id", spanCtx.TraceID().String()),
+			slog.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: handler.Handler.WithGroup(name)}
+}
+
+// fanoutHandler sends every record to all of its handlers.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func (handler *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range handler.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (handler *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range handler.handlers {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (handler *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(handler.handlers))
+	for i, h := range handler.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (handler *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(handler.handlers))
+	for i, h := range handler.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+// redactHandler replaces the value of any attribute whose key is in keys,
+// at any group depth. Keys are matched case-insensitively.
+type redactHandler struct {
+	slog.Handler
+	keys map[string]struct{}
+}
+
+func newRedactHandler(handler slog.Handler, keys []string) slog.Handler {
+	if len(keys) == 0 {
+		return handler
+	}
+	redactHandler := &redactHandler{Handler: handler, keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		redactHandler.keys[strings.ToLower(key)] = struct{}{}
+	}
+	return redactHandler
+}
+
+func (handler *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(handler.redact(attr))
+		return true
+	})
+	return handler.Handler.Handle(ctx, redacted)
+}
+
+func (handler *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = handler.redact(attr)
+	}
+	return &redactHandler{Handler: handler.Handler.WithAttrs(redacted), keys: handler.keys}
+}
+
+func (handler *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: handler.Handler.WithGroup(name), keys: handler.keys}
+}
+
+func (handler *redactHandler) redact(attr slog.Attr) slog.Attr {
+	if _, ok := handler.keys[strings.ToLower(attr.Key)]; ok {
+		return slog.String(attr.Key, redactedValue)
+	}
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup {
+		return attr
+	}
+	group := attr.Value.Group()
+	redacted := make([]any, len(group))
+	for i, member := range group {
+		redacted[i] = handler.redact(member)
+	}
+	return slog.Group(attr.Key, redacted...)
+}
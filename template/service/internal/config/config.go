@@ -1,7 +0,0 @@
-package config
-
-type App struct {
-	Server   Server   `json:"server" yaml:"server"`
-	Database Database `json:"database" yaml:"database"`
-	OTEL     OTEL     `json:"otel" yaml:"otel"`
-}
@@ -2,7 +2,7 @@ package config
 
 type Database struct {
 	Debug bool         `json:"debug" yaml:"debug" env:"DATABASE_DEBUG" default:"false"`
-	URI   string       `json:"uri" yaml:"uri" env:"DATABASE_URI" required:"true"`
+	URI   string       `json:"uri" yaml:"uri" env:"DATABASE_URI" secret:"true"`
 	Pool  DatabasePool `json:"pool" yaml:"pool"`
 }
 
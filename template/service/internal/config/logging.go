@@ -0,0 +1,35 @@
+package config
+
+type Logging struct {
+	Level      string        `json:"level" yaml:"level" env:"LOGGING_LEVEL" default:"info"`
+	Format     string        `json:"format" yaml:"format" env:"LOGGING_FORMAT" default:"json"`
+	Output     string        `json:"output" yaml:"output" env:"LOGGING_OUTPUT" default:"stdout"`
+	AddSource  bool          `json:"addSource" yaml:"addSource" env:"LOGGING_ADD_SOURCE" default:"false"`
+	RedactKeys []string      `json:"redactKeys" yaml:"redactKeys" env:"LOGGING_REDACT_KEYS" default:"password,token,authorization"`
+	OTEL       bool          `json:"otel" yaml:"otel" env:"LOGGING_OTEL" default:"false"`
+	File       LoggingFile   `json:"file" yaml:"file"`
+	Access     LoggingAccess `json:"access" yaml:"access"`
+	Admin      LoggingAdmin  `json:"admin" yaml:"admin"`
+}
+
+// LoggingFile configures the rotating file used when Output is "file".
+type LoggingFile struct {
+	Path       string `json:"path" yaml:"path" env:"LOGGING_FILE_PATH" default:"logs/app.log"`
+	MaxSizeMB  int    `json:"maxSizeMB" yaml:"maxSizeMB" env:"LOGGING_FILE_MAX_SIZE_MB" default:"100"`
+	MaxBackups int    `json:"maxBackups" yaml:"maxBackups" env:"LOGGING_FILE_MAX_BACKUPS" default:"5"`
+	MaxAgeDays int    `json:"maxAgeDays" yaml:"maxAgeDays" env:"LOGGING_FILE_MAX_AGE_DAYS" default:"30"`
+	Compress   bool   `json:"compress" yaml:"compress" env:"LOGGING_FILE_COMPRESS" default:"true"`
+}
+
+// LoggingAccess controls the per-request access log. Successful requests to
+// ProbePaths are logged with probability ProbeSampleRate (0 skips them).
+type LoggingAccess struct {
+	ProbePaths      []string `json:"probePaths" yaml:"probePaths" env:"LOGGING_ACCESS_PROBE_PATHS" default:"/health,/ready"`
+	ProbeSampleRate float64  `json:"probeSampleRate" yaml:"probeSampleRate" env:"LOGGING_ACCESS_PROBE_SAMPLE_RATE" default:"0"`
+}
+
+// LoggingAdmin exposes /admin/log-level, guarded by a bearer token.
+type LoggingAdmin struct {
+	Enabled bool   `json:"enabled" yaml:"enabled" env:"LOGGING_ADMIN_ENABLED" default:"false"`
+	Token   string `json:"token" yaml:"token" env:"LOGGING_ADMIN_TOKEN" secret:"true"`
+}
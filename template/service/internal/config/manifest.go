@@ -1,5 +1,5 @@
 package config
 
-const (
-	Version = "1.0.0"
-)
+// Version is the version of the build, set with
+// -ldflags "-X <module>/internal/config.Version=<version>" (see Dockerfile).
+var Version = "1.0.0"
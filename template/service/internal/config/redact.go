@@ -0,0 +1,41 @@
+package config
+
+import "reflect"
+
+const redacted = "******"
+
+// Redact masks the non-empty string fields tagged secret:"true" in the
+// struct pointed to by v, e.g. before printing the configuration.
+func Redact(v any) {
+	redact(reflect.ValueOf(v).Elem())
+}
+
+func redact(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if value.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String {
+				if field.String() != "" {
+					field.SetString(redacted)
+				}
+				continue
+			}
+			redact(field)
+		}
+	case reflect.Slice:
+		if value.IsNil() {
+			return
+		}
+		// Copy first so the caller's slices are left untouched.
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(copied, value)
+		value.Set(copied)
+		for i := 0; i < copied.Len(); i++ {
+			redact(copied.Index(i))
+		}
+	}
+}
@@ -3,9 +3,11 @@ package config
 type Server struct {
 	Debug            bool     `json:"debug" yaml:"debug" env:"SERVER_DEBUG" default:"false"`
 	Addr             string   `json:"addr" yaml:"addr" env:"SERVER_ADDR" default:"0.0.0.0:8080"`
+	APIPrefix        string   `json:"apiPrefix" yaml:"apiPrefix" env:"SERVER_API_PREFIX" default:"/api"`
 	AllowedOrigins   []string `json:"allowedOrigins" yaml:"allowedOrigins" env:"SERVER_ALLOWED_ORIGINS" default:"*"`
 	AllowedMethods   []string `json:"allowedMethods" yaml:"allowedMethods" env:"SERVER_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
 	AllowedHeaders   []string `json:"allowedHeaders" yaml:"allowedHeaders" env:"SERVER_ALLOWED_HEADERS" default:"Content-Type,Authorization"`
-	AllowCredentials bool     `json:"allowCredentials" yaml:"allowCredentials" env:"SERVER_ALLOW_CREDENTIALS" default:"true"`
+	AllowCredentials bool     `json:"allowCredentials" yaml:"allowCredentials" env:"SERVER_ALLOW_CREDENTIALS" default:"false"`
 	MaxAge           string   `json:"maxAge" yaml:"maxAge" env:"SERVER_MAX_AGE" default:"1h"`
+	ShutdownTimeout  string   `json:"shutdownTimeout" yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
 }
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// validator collects every configuration problem so they can be reported at
+// once. Names are the YAML paths, e.g. server.maxAge.
+type validator struct {
+	problems []error
+}
+
+func (v *validator) check(ok bool, name string, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) required(name string, value string) {
+	v.check(value != "", name, "is required")
+}
+
+func (v *validator) duration(name string, value string) {
+	d, err := time.ParseDuration(value)
+	v.check(err == nil && d >= 0, name, "invalid duration %q", value)
+}
+
+func (v *validator) oneOf(name string, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), name, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.problems...)
+}
+
+func (server Server) validate(v *validator) {
+	v.required("server.addr", server.Addr)
+	v.check(strings.HasPrefix(server.APIPrefix, "/"), "server.apiPrefix", "%q must start with /", server.APIPrefix)
+	v.duration("server.maxAge", server.MaxAge)
+	v.duration("server.shutdownTimeout", server.ShutdownTimeout)
+	v.check(len(server.AllowedOrigins) > 0, "server.allowedOrigins", "at least one origin is required")
+	for _, origin := range server.AllowedOrigins {
+		if origin == "*" {
+			// Browsers refuse credentials for a wildcard origin, and
+			// gin-contrib/cors rejects the combination.
+			v.check(!server.AllowCredentials, "server.allowedOrigins", `"*" cannot be combined with allowCredentials: true, list the origins instead`)
+			continue
+		}
+		v.check(strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"), "server.allowedOrigins", "origin %q must start with http:// or https://", origin)
+	}
+}
+
+func (database Database) validate(v *validator) {
+	v.required("database.uri", database.URI)
+	if database.Pool.Enabled {
+		v.check(database.Pool.MaxOpenConns >= 0, "database.pool.maxOpenConns", "must not be negative")
+		v.check(database.Pool.MaxIdleConns >= 0, "database.pool.maxIdleConns", "must not be negative")
+	}
+}
+
+func (otel OTEL) validate(v *validator) {
+	if otel.Enabled {
+		v.required("otel.endpoint", otel.Endpoint)
+		v.required("otel.serviceName", otel.ServiceName)
+	}
+}
+
+func (logging Logging) validate(v *validator) {
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(logging.Level)) == nil, "logging.level", "invalid level %q", logging.Level)
+	v.oneOf("logging.format", logging.Format, "json", "text")
+	v.oneOf("logging.output", logging.Output, "stdout", "stderr", "file")
+	if logging.Output == "file" {
+		v.required("logging.file.path", logging.File.Path)
+	}
+	v.check(logging.Access.ProbeSampleRate >= 0 && logging.Access.ProbeSampleRate <= 1, "logging.access.probeSampleRate", "must be between 0 and 1")
+	if logging.Admin.Enabled {
+		v.required("logging.admin.token", logging.Admin.Token)
+	}
+}
@@ -4,6 +4,11 @@ type ErrorCode string
 
 const (
 	ErrorCodeInternalServerError ErrorCode = "internal_server_error"
+	ErrorCodeBadRequest          ErrorCode = "bad_request"
+	ErrorCodeUnauthorized        ErrorCode = "unauthorized"
+	ErrorCodeForbidden           ErrorCode = "forbidden"
+	ErrorCodeRateLimited         ErrorCode = "rate_limited"
+	ErrorCodeNotImplemented      ErrorCode = "not_implemented"
 )
 
 type Error struct {
@@ -16,6 +21,10 @@ type HttpError struct {
 	StatusCode int `json:"status_code"`
 }
 
+func (err *Error) Error() string {
+	return string(err.Code) + ": " + err.Description
+}
+
 func NewError(code ErrorCode, description string) *Error {
 	return &Error{Code: code, Description: description}
 }
@@ -4,4 +4,5 @@ import "github.com/google/wire"
 
 var ProviderSetHTTPServer = wire.NewSet(
 	NewHTTPServer,
-)
\ No newline at end of file
+	NewServer,
+)
@@ -0,0 +1,9 @@
+package schema
+
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required" example:"debug"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+}
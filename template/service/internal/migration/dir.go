@@ -0,0 +1,166 @@
+package migration
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// HashFileName is the atlas integrity file kept next to the migrations.
+	HashFileName = "atlas.sum"
+	// DownDir holds the optional revert scripts, one per migration file with
+	// the same name. atlas only reads the top level, so they are not hashed.
+	DownDir = "down"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// File is a single atlas migration file, e.g. 20240101120000_create_users.sql.
+type File struct {
+	Name        string
+	Version     string
+	Description string
+	Bytes       []byte
+}
+
+// Hash returns the checksum of the file content stored with its revision.
+func (file File) Hash() string {
+	sum := sha256.Sum256(file.Bytes)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadFiles returns the migration files of dir ordered by version.
+func ReadFiles(dir string) ([]File, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	files := make([]File, 0, len(names))
+	for _, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, newFile(filepath.Base(name), b))
+	}
+	return files, nil
+}
+
+func newFile(name string, b []byte) File {
+	version, description, _ := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+	return File{Name: name, Version: version, Description: description, Bytes: b}
+}
+
+// fileName rebuilds the file name of a revision whose file may be gone.
+func fileName(version string, description string) string {
+	if description == "" {
+		return version + ".sql"
+	}
+	return version + "_" + description + ".sql"
+}
+
+// ReadDownFile returns the revert script of the migration file name.
+func ReadDownFile(dir string, name string) (File, error) {
+	b, err := os.ReadFile(filepath.Join(dir, DownDir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return File{}, fmt.Errorf("no down migration %s", filepath.Join(DownDir, name))
+		}
+		return File{}, err
+	}
+	return newFile(name, b), nil
+}
+
+// HashFile is the content of atlas.sum. It uses the same algorithm as
+// `atlas migrate hash`, so either tool can maintain the directory.
+type HashFile []struct{ Name, Hash string }
+
+func NewHashFile(files []File) HashFile {
+	var (
+		hashFile HashFile
+		h        = sha256.New()
+	)
+	for _, file := range files {
+		h.Write([]byte(file.Name))
+		if mode, ok := directive(string(file.Bytes), "sum"); ok && mode == "ignore" {
+			continue
+		}
+		h.Write(file.Bytes)
+		hashFile = append(hashFile, struct{ Name, Hash string }{file.Name, base64.StdEncoding.EncodeToString(h.Sum(nil))})
+	}
+	return hashFile
+}
+
+func (hashFile HashFile) Sum() string {
+	h := sha256.New()
+	for _, entry := range hashFile {
+		h.Write([]byte(entry.Name))
+		h.Write([]byte(entry.Hash))
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (hashFile HashFile) MarshalText() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "h1:%s\n", hashFile.Sum())
+	for _, entry := range hashFile {
+		fmt.Fprintf(buf, "%s h1:%s\n", entry.Name, entry.Hash)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteHashFile rewrites atlas.sum for the files of dir.
+func WriteHashFile(dir string, files []File) error {
+	b, err := NewHashFile(files).MarshalText()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, HashFileName), b, 0o644)
+}
+
+// Verify checks atlas.sum against the files of dir, so that edited or
+// hand-added migrations are caught before they are applied.
+func Verify(dir string, files []File) error {
+	b, err := os.ReadFile(filepath.Join(dir, HashFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && len(files) == 0 {
+			return nil
+		}
+		return fmt.Errorf("read %s: %w", HashFileName, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Scan()
+	sum := strings.TrimPrefix(scanner.Text(), "h1:")
+	if sum != NewHashFile(files).Sum() {
+		return fmt.Errorf("%w: %s is out of date, run `atlas migrate hash` or `migrate create`", ErrChecksumMismatch, HashFileName)
+	}
+	return nil
+}
+
+// directive returns the value of an atlas file directive such as
+// `-- atlas:txmode none`. Directives must precede the first statement.
+func directive(content string, name string) (string, bool) {
+	prefix := "-- atlas:" + name
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return "", false
+		}
+		if value, ok := strings.CutPrefix(line, prefix); ok && (value == "" || value[0] == ' ' || value[0] == '\t') {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const lockPollInterval = 500 * time.Millisecond
+
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// withLock runs fn on a single connection while holding a database-level
+// advisory lock, so concurrent `migrate` runs (e.g. several replicas starting
+// at once) apply each migration only once.
+func withLock(ctx context.Context, db *gorm.DB, name string, timeout time.Duration, fn func(conn *gorm.DB) error) error {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		lock, unlock, ok := advisoryLock(conn, name)
+		if !ok {
+			// The dialect has no advisory locks (e.g. sqlite), which is a
+			// single-writer database anyway.
+			return fn(conn)
+		}
+		deadline := time.Now().Add(timeout)
+		for {
+			var acquired bool
+			if err := conn.Raw(lock.sql, lock.args...).Scan(&acquired).Error; err != nil {
+				return err
+			}
+			if acquired {
+				break
+			}
+			if time.Now().After(deadline) {
+				return ErrLockTimeout
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(lockPollInterval):
+			}
+		}
+		defer conn.Exec(unlock.sql, unlock.args...)
+		return fn(conn)
+	})
+}
+
+type query struct {
+	sql  string
+	args []any
+}
+
+func advisoryLock(conn *gorm.DB, name string) (query, query, bool) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		return query{"SELECT GET_LOCK(?, 0) = 1", []any{name}}, query{"SELECT RELEASE_LOCK(?)", []any{name}}, true
+	case "postgres":
+		h := fnv.New64a()
+		h.Write([]byte(name))
+		key := int64(h.Sum64())
+		return query{"SELECT pg_try_advisory_lock(?)", []any{key}}, query{"SELECT pg_advisory_unlock(?)", []any{key}}, true
+	default:
+		return query{}, query{}, false
+	}
+}
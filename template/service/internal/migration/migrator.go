@@ -0,0 +1,278 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StatePending  = "pending"
+	StateApplied  = "applied"
+	StateModified = "modified"
+	StateNoFile   = "missing file"
+)
+
+const (
+	lockName           = "schema_migrations"
+	versionLayout      = "20060102150405"
+	defaultLockTimeout = time.Minute
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// Revision records an applied migration file.
+type Revision struct {
+	Version       string `gorm:"primaryKey;size:64"`
+	Description   string `gorm:"size:255"`
+	Hash          string `gorm:"size:64"`
+	ExecutionTime time.Duration
+	AppliedAt     time.Time
+}
+
+func (Revision) TableName() string {
+	return "schema_revisions"
+}
+
+type Options struct {
+	// Dir is the atlas migration directory.
+	Dir string
+	// DryRun prints the statements instead of executing them.
+	DryRun bool
+	// LockTimeout bounds the wait for a concurrent run to finish.
+	LockTimeout time.Duration
+	// Out receives progress and, on a dry run, the SQL.
+	Out io.Writer
+}
+
+type Status struct {
+	Version     string
+	Description string
+	State       string
+	AppliedAt   time.Time
+}
+
+// Migrator applies the atlas-format files of a directory with an in-process
+// runner, so deployments need the service binary only.
+type Migrator struct {
+	db *gorm.DB
+}
+
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{db: db}
+}
+
+// Up applies up to steps pending migrations, all of them when steps is 0.
+func (migrator *Migrator) Up(ctx context.Context, options Options, steps int) error {
+	files, err := ReadFiles(options.Dir)
+	if err != nil {
+		return err
+	}
+	if err := Verify(options.Dir, files); err != nil {
+		return err
+	}
+	return migrator.locked(ctx, options, func(conn *gorm.DB) error {
+		revisions, err := migrator.revisions(conn, !options.DryRun)
+		if err != nil {
+			return err
+		}
+		var pending []File
+		for _, file := range files {
+			revision, ok := revisions[file.Version]
+			if !ok {
+				pending = append(pending, file)
+				continue
+			}
+			if revision.Hash != file.Hash() {
+				return fmt.Errorf("migration %s was modified after it was applied", file.Name)
+			}
+		}
+		if steps > 0 && len(pending) > steps {
+			pending = pending[:steps]
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(options.Out, "No pending migrations")
+			return nil
+		}
+		for _, file := range pending {
+			revision := Revision{Version: file.Version, Description: file.Description, Hash: file.Hash()}
+			if err := migrator.apply(conn, options, "Migrating", file, func(tx *gorm.DB, duration time.Duration) error {
+				revision.ExecutionTime, revision.AppliedAt = duration, time.Now().UTC()
+				return tx.Create(&revision).Error
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the last steps applied migrations using the scripts in the
+// down/ directory.
+func (migrator *Migrator) Down(ctx context.Context, options Options, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
+	return migrator.locked(ctx, options, func(conn *gorm.DB) error {
+		revisions, err := migrator.revisions(conn, false)
+		if err != nil {
+			return err
+		}
+		applied := sortedRevisions(revisions)
+		if len(applied) == 0 {
+			fmt.Fprintln(options.Out, "No applied migrations")
+			return nil
+		}
+		for i := len(applied) - 1; i >= 0 && i >= len(applied)-steps; i-- {
+			revision := applied[i]
+			file, err := ReadDownFile(options.Dir, fileName(revision.Version, revision.Description))
+			if err != nil {
+				return err
+			}
+			if err := migrator.apply(conn, options, "Reverting", file, func(tx *gorm.DB, duration time.Duration) error {
+				return tx.Delete(&revision).Error
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every migration file and applied revision in version order.
+func (migrator *Migrator) Status(ctx context.Context, dir string) ([]Status, error) {
+	files, err := ReadFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := migrator.revisions(migrator.db.WithContext(ctx), false)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(files))
+	for _, file := range files {
+		status := Status{Version: file.Version, Description: file.Description, State: StatePending}
+		if revision, ok := revisions[file.Version]; ok {
+			status.State, status.AppliedAt = StateApplied, revision.AppliedAt
+			if revision.Hash != file.Hash() {
+				status.State = StateModified
+			}
+			delete(revisions, file.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, revision := range revisions {
+		statuses = append(statuses, Status{Version: revision.Version, Description: revision.Description, State: StateNoFile, AppliedAt: revision.AppliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func (migrator *Migrator) locked(ctx context.Context, options Options, fn func(conn *gorm.DB) error) error {
+	if options.Out == nil {
+		options.Out = io.Discard
+	}
+	if options.LockTimeout <= 0 {
+		options.LockTimeout = defaultLockTimeout
+	}
+	return withLock(ctx, migrator.db, lockName, options.LockTimeout, fn)
+}
+
+// revisions returns the applied revisions by version. The revision table is
+// created on demand unless create is false, in which case a missing table
+// means nothing was applied yet.
+func (migrator *Migrator) revisions(conn *gorm.DB, create bool) (map[string]Revision, error) {
+	if !conn.Migrator().HasTable(&Revision{}) {
+		if !create {
+			return map[string]Revision{}, nil
+		}
+		if err := conn.AutoMigrate(&Revision{}); err != nil {
+			return nil, err
+		}
+	}
+	var list []Revision
+	if err := conn.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	revisions := make(map[string]Revision, len(list))
+	for _, revision := range list {
+		revisions[revision.Version] = revision
+	}
+	return revisions, nil
+}
+
+// apply executes the statements of file and then record, inside a single
+// transaction unless the file opts out with `-- atlas:txmode none`.
+func (migrator *Migrator) apply(conn *gorm.DB, options Options, action string, file File, record func(tx *gorm.DB, duration time.Duration) error) error {
+	stmts, err := Statements(string(file.Bytes), conn.Dialector.Name())
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", file.Name, err)
+	}
+	if options.DryRun {
+		fmt.Fprintf(options.Out, "-- %s\n", file.Name)
+		for _, stmt := range stmts {
+			fmt.Fprintf(options.Out, "%s;\n", stmt)
+		}
+		return nil
+	}
+	fmt.Fprintf(options.Out, "%s %s (%d statements)\n", action, file.Name, len(stmts))
+	start := time.Now()
+	run := func(tx *gorm.DB) error {
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%s: %w\n%s", file.Name, err, stmt)
+			}
+		}
+		return record(tx, time.Since(start))
+	}
+	if mode, _ := directive(string(file.Bytes), "txmode"); mode == "none" {
+		err = run(conn)
+	} else {
+		err = conn.Transaction(run)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(options.Out, "Done %s in %s\n", file.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Create adds an empty migration file named after the current time, with its
+// down script, and updates atlas.sum.
+func Create(dir string, name string) (string, error) {
+	if !nameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid migration name %q, use letters, digits and underscores", name)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, DownDir), 0o755); err != nil {
+		return "", err
+	}
+	base := fileName(time.Now().UTC().Format(versionLayout), name)
+	path := filepath.Join(dir, base)
+	if err := os.WriteFile(path, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, DownDir, base), []byte("-- revert "+name+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	files, err := ReadFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	return path, WriteHashFile(dir, files)
+}
+
+func sortedRevisions(revisions map[string]Revision) []Revision {
+	list := make([]Revision, 0, len(revisions))
+	for _, revision := range revisions {
+		list = append(list, revision)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statements splits the content of a migration file for the gorm dialect
+// (mysql, postgres, sqlite...) into statements. It honours the
+// `-- atlas:delimiter` directive and otherwise splits on semicolons outside
+// of quotes, comments and dollar-quoted bodies. Only MySQL has # comments:
+// in PostgreSQL # starts operators such as #> and #-.
+func Statements(content string, dialect string) ([]string, error) {
+	if delimiter, ok := directive(content, "delimiter"); ok {
+		delimiter = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(delimiter)
+		if delimiter == "" {
+			return nil, fmt.Errorf("empty atlas:delimiter")
+		}
+		return splitDelimiter(content, delimiter, dialect), nil
+	}
+	return splitSemicolon(content, dialect)
+}
+
+// hashComments reports whether # starts a line comment in dialect.
+func hashComments(dialect string) bool {
+	return dialect == "mysql"
+}
+
+func splitDelimiter(content string, delimiter string, dialect string) []string {
+	var stmts []string
+	for _, stmt := range strings.Split(content, delimiter) {
+		if stmt = strings.TrimSpace(stmt); stmt != "" && !onlyComments(stmt, dialect) {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+func splitSemicolon(content string, dialect string) ([]string, error) {
+	var (
+		stmts []string
+		start int
+		code  bool
+	)
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end, err := skipQuoted(content, i, c)
+			if err != nil {
+				return nil, err
+			}
+			i, code = end, true
+		case c == '-' && strings.HasPrefix(content[i:], "--"), c == '#' && hashComments(dialect):
+			i = skipLine(content, i)
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 3
+		case c == '$':
+			if tag, ok := dollarTag(content[i:]); ok {
+				end := strings.Index(content[i+len(tag):], tag)
+				if end == -1 {
+					return nil, fmt.Errorf("unterminated %s body at offset %d", tag, i)
+				}
+				i, code = i+len(tag)+end+len(tag)-1, true
+				continue
+			}
+			code = true
+		case c == ';':
+			if code {
+				stmts = append(stmts, strings.TrimSpace(content[start:i]))
+			}
+			start, code = i+1, false
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			code = true
+		}
+	}
+	if code {
+		stmts = append(stmts, strings.TrimSpace(content[start:]))
+	}
+	return stmts, nil
+}
+
+func skipQuoted(content string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			// A doubled quote is an escaped quote.
+			if i+1 < len(content) && content[i+1] == quote {
+				i++
+				continue
+			}
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated %c quote at offset %d", quote, start)
+}
+
+func skipLine(content string, start int) int {
+	if end := strings.IndexByte(content[start:], '\n'); end != -1 {
+		return start + end
+	}
+	return len(content)
+}
+
+// dollarTag returns the PostgreSQL dollar-quote tag at the start of s, such
+// as $$ or $body$.
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1], true
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func onlyComments(stmt string, dialect string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") && !(hashComments(dialect) && strings.HasPrefix(line, "#")) {
+			return false
+		}
+	}
+	return true
+}
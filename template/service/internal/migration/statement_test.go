@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	tests := map[string]struct {
+		dialect  string
+		content  string
+		expected []string
+	}{
+		"semicolons": {
+			dialect:  "postgres",
+			content:  "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n",
+			expected: []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"},
+		},
+		"trailing statement without semicolon": {
+			dialect:  "postgres",
+			content:  "INSERT INTO a VALUES (1);\nINSERT INTO a VALUES (2)\n",
+			expected: []string{"INSERT INTO a VALUES (1)", "INSERT INTO a VALUES (2)"},
+		},
+		"quotes": {
+			dialect: "postgres",
+			content: `INSERT INTO a VALUES ('x;y', "c;d");` + "\nSELECT `e;f`;",
+			expected: []string{
+				`INSERT INTO a VALUES ('x;y', "c;d")`,
+				"SELECT `e;f`",
+			},
+		},
+		"doubled quotes": {
+			dialect:  "postgres",
+			content:  "INSERT INTO a VALUES ('it''s; fine');SELECT 1;",
+			expected: []string{"INSERT INTO a VALUES ('it''s; fine')", "SELECT 1"},
+		},
+		"backslash escaped quotes": {
+			dialect:  "mysql",
+			content:  `INSERT INTO a VALUES ('it\'s; fine', "say \"hi;\"");SELECT 1;`,
+			expected: []string{`INSERT INTO a VALUES ('it\'s; fine', "say \"hi;\"")`, "SELECT 1"},
+		},
+		"dollar-quoted bodies": {
+			dialect: "postgres",
+			content: "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;\n" +
+				"CREATE FUNCTION g() RETURNS int AS $body$ SELECT $$;$$; $body$ LANGUAGE sql;\n",
+			expected: []string{
+				"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql",
+				"CREATE FUNCTION g() RETURNS int AS $body$ SELECT $$;$$; $body$ LANGUAGE sql",
+			},
+		},
+		"positional parameters": {
+			dialect:  "postgres",
+			content:  "PREPARE p AS SELECT $1;",
+			expected: []string{"PREPARE p AS SELECT $1"},
+		},
+		"comments": {
+			dialect: "postgres",
+			content: "-- creates a; really\nCREATE TABLE a (id int); -- trailing;\n" +
+				"/* block; comment */\nCREATE TABLE b (id int);\n-- only a comment\n",
+			expected: []string{
+				"-- creates a; really\nCREATE TABLE a (id int)",
+				"-- trailing;\n/* block; comment */\nCREATE TABLE b (id int)",
+			},
+		},
+		"mysql hash comments": {
+			dialect:  "mysql",
+			content:  "# creates a; really\nCREATE TABLE a (id int);\n# the end;\n",
+			expected: []string{"# creates a; really\nCREATE TABLE a (id int)"},
+		},
+		"postgres hash operators": {
+			dialect: "postgres",
+			content: "SELECT data #> '{a,b}', data #>> '{a}', data #- '{c}', 5 # 3 FROM a;\nSELECT 1;",
+			expected: []string{
+				"SELECT data #> '{a,b}', data #>> '{a}', data #- '{c}', 5 # 3 FROM a",
+				"SELECT 1",
+			},
+		},
+		"delimiter directive": {
+			dialect:  "mysql",
+			content:  "-- atlas:delimiter //\n\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\n# a comment\n//\n",
+			expected: []string{"CREATE PROCEDURE p() BEGIN SELECT 1; END"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stmts, err := Statements(test.content, test.dialect)
+			if err != nil {
+				t.Fatalf("Statements: %v", err)
+			}
+			if !reflect.DeepEqual(stmts, test.expected) {
+				t.Errorf("got %q, want %q", stmts, test.expected)
+			}
+		})
+	}
+}
+
+func TestStatementsErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated quote":   "INSERT INTO a VALUES ('x);",
+		"unterminated comment": "/* SELECT 1;",
+		"unterminated body":    "CREATE FUNCTION f() AS $$ SELECT 1;",
+		"empty delimiter":      "-- atlas:delimiter \nSELECT 1;",
+	}
+	for name, content := range tests {
+		if _, err := Statements(content, "postgres"); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
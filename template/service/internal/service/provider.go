@@ -2,4 +2,6 @@ package service
 
 import "github.com/google/wire"
 
-var ProviderSetService = wire.NewSet()
+var ProviderSetService = wire.NewSet(
+	NewReadinessService,
+)
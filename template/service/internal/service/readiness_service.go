@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const readinessTimeout = 3 * time.Second
+
+// ReadinessChecker reports whether a dependency can serve traffic.
+type ReadinessChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type ReadinessResult struct {
+	Name string
+	Err  error
+}
+
+// ReadinessService runs the readiness checks shared by every entrypoint.
+type ReadinessService struct {
+	checkers []ReadinessChecker
+}
+
+func NewReadinessService(checkers []ReadinessChecker) *ReadinessService {
+	return &ReadinessService{checkers: checkers}
+}
+
+// Check runs every checker concurrently and reports whether all of them passed.
+func (readinessService *ReadinessService) Check(ctx context.Context) (bool, []ReadinessResult) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	results := make([]ReadinessResult, len(readinessService.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range readinessService.checkers {
+		wg.Add(1)
+		go func(i int, checker ReadinessChecker) {
+			defer wg.Done()
+			results[i] = ReadinessResult{Name: checker.Name(), Err: checker.Check(ctx)}
+		}(i, checker)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Err != nil {
+			ready = false
+		}
+	}
+	return ready, results
+}
@@ -0,0 +1,136 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Request describes a request sent by Server.Do. Body is encoded as JSON
+// unless it is nil or already an io.Reader.
+type Request struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    any
+	Bearer  string
+	Address string
+}
+
+// Response is the recorded response of a request.
+type Response struct {
+	*httptest.ResponseRecorder
+}
+
+// Do serves request through the engine.
+func (server *Server) Do(t testing.TB, request Request) *Response {
+	t.Helper()
+	var body io.Reader
+	switch value := request.Body.(type) {
+	case nil:
+	case io.Reader:
+		body = value
+	default:
+		payload, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("encode request body: %v", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpRequest := httptest.NewRequest(method, request.Path, body)
+	for key, values := range request.Header {
+		httpRequest.Header[key] = values
+	}
+	if body != nil && httpRequest.Header.Get("Content-Type") == "" {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if request.Bearer != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+request.Bearer)
+	}
+	if request.Address != "" {
+		httpRequest.RemoteAddr = request.Address
+	}
+	recorder := httptest.NewRecorder()
+	server.Engine.ServeHTTP(recorder, httpRequest)
+	return &Response{ResponseRecorder: recorder}
+}
+
+// Get is a shorthand for a GET request without body.
+func (server *Server) Get(t testing.TB, path string) *Response {
+	t.Helper()
+	return server.Do(t, Request{Method: http.MethodGet, Path: path})
+}
+
+// AssertStatus fails the test when the status is not expected.
+func (response *Response) AssertStatus(t testing.TB, expected int) *Response {
+	t.Helper()
+	if response.Code != expected {
+		t.Fatalf("status: got %d, want %d, body %s", response.Code, expected, response.Body.String())
+	}
+	return response
+}
+
+// DecodeJSON decodes the body into v, rejecting unknown fields so schemas do
+// not drift from the handlers.
+func (response *Response) DecodeJSON(t testing.TB, v any) {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(response.Body.Bytes()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("decode response body %s: %v", response.Body.String(), err)
+	}
+}
+
+// AssertJSON fails the test when the body is not the same JSON document as
+// expected, ignoring formatting and key order. expected is either a string or
+// a value encoded with encoding/json.
+func (response *Response) AssertJSON(t testing.TB, expected any) {
+	t.Helper()
+	want, ok := expected.(string)
+	if !ok {
+		payload, err := json.Marshal(expected)
+		if err != nil {
+			t.Fatalf("encode expected body: %v", err)
+		}
+		want = string(payload)
+	}
+	wantJSON, gotJSON := normalizeJSON(t, []byte(want)), normalizeJSON(t, response.Body.Bytes())
+	if wantJSON != gotJSON {
+		t.Fatalf("body:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func normalizeJSON(t testing.TB, data []byte) string {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encode JSON: %v", err)
+	}
+	return string(normalized)
+}
+
+// LogRecords decodes the JSON records written by the application logger.
+func (server *Server) LogRecords(t testing.TB) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(server.Logs.Bytes()))
+	for decoder.More() {
+		record := map[string]any{}
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("decode log record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,47 @@
+// Package env reads typed values from the environment. It is shared by the
+// modules of the workspace, import it as <workspace module>/pkg/env.
+package env
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// String returns the value of key, or fallback when it is unset or empty.
+func String(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Int returns the value of key parsed as an int, or fallback when it is unset.
+func Int(key string, fallback int) (int, error) {
+	return parse(key, fallback, strconv.Atoi)
+}
+
+// Bool returns the value of key parsed by strconv.ParseBool, or fallback when
+// it is unset.
+func Bool(key string, fallback bool) (bool, error) {
+	return parse(key, fallback, strconv.ParseBool)
+}
+
+// Duration returns the value of key parsed by time.ParseDuration, or fallback
+// when it is unset.
+func Duration(key string, fallback time.Duration) (time.Duration, error) {
+	return parse(key, fallback, time.ParseDuration)
+}
+
+func parse[T any](key string, fallback T, parseValue func(string) (T, error)) (T, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := parseValue(value)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return parsed, nil
+}
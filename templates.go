@@ -0,0 +1,586 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateManifestFile declares how a template builds on another one. Like
+// every "_" entry, it is never rendered into the project.
+const templateManifestFile = "_template.yaml"
+
+var reVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// builtinFS holds the built-in templates and partials under template/: the
+// tree embedded in the binary, replaced by the tests.
+var builtinFS fs.FS = templateFS
+
+// templateManifest is the _template.yaml of a template, e.g.
+//
+//	name: api
+//	description: HTTP service of the payments team
+//	version: 1.2.0
+//	tags: [http, payments]
+//	extends: service
+//	delete:
+//	  - bin/run.sh
+//	include:
+//	  - bin/test.sh
+//	variables:
+//	  - name: Team
+//	    default: platform
+//	hooks:
+//	  post:
+//	    - git init
+type templateManifest struct {
+	// Name, Description, Version, Maintainer, GoVersion (the minimum Go
+	// version of projects) and Tags describe the template in list and show.
+	// The first line of Description is its summary.
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Version     string   `yaml:"version"`
+	Maintainer  string   `yaml:"maintainer"`
+	GoVersion   string   `yaml:"goVersion"`
+	Tags        []string `yaml:"tags"`
+	// Components describes the optional components of _components/.
+	Components map[string]string `yaml:"components"`
+	// Extends is the parent template: a built-in name, a directory
+	// (relative to this template) or a git repository.
+	Extends string `yaml:"extends"`
+	// Delete lists project paths, templated and globbed, rendered by the
+	// parents that this template removes before adding its own files.
+	Delete []string `yaml:"delete"`
+	// Include lists files and directories of _partials/ rendered into the
+	// project at the same path, before the files of this template.
+	Include   []string           `yaml:"include"`
+	Variables []templateVariable `yaml:"variables"`
+	Hooks     templateHooks      `yaml:"hooks"`
+}
+
+// templateVariable is a value of the project, set with --set or the Vars of
+// values.yaml and read by templates as {{.Vars.<Name>}}.
+type templateVariable struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+	Required    bool   `yaml:"required" json:"required"`
+}
+
+// templateHooks are shell commands, templated with the values, run in the
+// project after it is set up.
+type templateHooks struct {
+	Post []string `yaml:"post"`
+}
+
+// projectTemplate is a template resolved with its ancestors. Rendering
+// overlays its files on the rendered tree of its parent.
+type projectTemplate struct {
+	// Name is the reference the template was resolved from.
+	Name string
+	// ID identifies where the template lives, to detect cycles.
+	ID string
+	// Dir is the directory of the template on disk, "" for built-ins.
+	Dir      string
+	FS       fs.FS
+	Root     string
+	Manifest templateManifest
+	Parent   *projectTemplate
+	// Source is where the template was found, with Path the directory of
+	// the template in it, so that beginning diff can render it again.
+	Source templateSource
+	Path   string
+}
+
+// resolveTemplate locates the template ref and the chain of templates it
+// extends.
+func resolveTemplate(ref string) (*projectTemplate, error) {
+	return resolveTemplateFrom(ref, "", nil)
+}
+
+func resolveTemplateFrom(ref string, baseDir string, chain []string) (*projectTemplate, error) {
+	t, err := locateTemplate(ref, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.resolve(chain); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// resolve reads the manifest of a located template and resolves the
+// templates it extends. chain holds the IDs of the templates extending it.
+func (t *projectTemplate) resolve(chain []string) error {
+	for _, id := range chain {
+		if id == t.ID {
+			return fmt.Errorf("template inheritance cycle: %s", strings.Join(append(chain, t.ID), " → "))
+		}
+	}
+
+	content, err := fs.ReadFile(t.FS, path.Join(t.Root, templateManifestFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&t.Manifest); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s of %s: %w", templateManifestFile, t.ID, err)
+		}
+	}
+
+	if t.Manifest.Extends != "" {
+		parent, err := resolveTemplateFrom(t.Manifest.Extends, t.Dir, append(chain, t.ID))
+		if err != nil {
+			return fmt.Errorf("template %s extends %s: %w", t.ID, t.Manifest.Extends, err)
+		}
+		t.Parent = parent
+	}
+	return nil
+}
+
+// locateTemplate finds a template without its parents. Directories are
+// relative to baseDir, or to the working directory when it is empty.
+func locateTemplate(ref string, baseDir string) (*projectTemplate, error) {
+	// Aliases and sources of the user configuration come first, so that
+	// ourorg/api is not taken for a directory
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	if alias, ok := cfg.Aliases[ref]; ok {
+		ref = alias
+	}
+	if t, ok, err := locateRegistryTemplate(cfg, ref); ok {
+		return t, err
+	}
+
+	if url, rev, subdir, ok := parseGitTemplateRef(ref); ok {
+		checkout, err := fetchGitTemplate(url, rev, true)
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Join(checkout, filepath.FromSlash(subdir))
+		if !isDir(dir) {
+			return nil, fmt.Errorf("%s has no directory %s", url, subdir)
+		}
+		id := url
+		if subdir != "" {
+			id += "//" + subdir
+		}
+		location := url
+		if rev != "" {
+			id += "#" + rev
+			location += "#" + rev
+		}
+		return &projectTemplate{
+			Name: ref, ID: id, Dir: dir, FS: os.DirFS(dir), Root: ".",
+			Source: templateSource{Kind: sourceKindGit, Location: location}, Path: subdir,
+		}, nil
+	}
+
+	if isDirTemplateRef(ref) {
+		dir := ref
+		if strings.HasPrefix(dir, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, dir[2:])
+		} else if !filepath.IsAbs(dir) && baseDir != "" {
+			dir = filepath.Join(baseDir, dir)
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !isDir(dir) {
+			return nil, fmt.Errorf("template directory %s not found", dir)
+		}
+		return &projectTemplate{
+			Name: ref, ID: dir, Dir: dir, FS: os.DirFS(dir), Root: ".",
+			Source: templateSource{Kind: sourceKindDir, Location: dir},
+		}, nil
+	}
+
+	if _, err := fs.Stat(builtinFS, "template/"+ref); err != nil || ref == "" || strings.HasPrefix(ref, "_") {
+		return nil, fmt.Errorf("unknown template %q, expected a built-in template (%s), a template of a source, a directory or a git repository", ref, strings.Join(builtinTemplates(), ", "))
+	}
+	return &projectTemplate{
+		Name: ref, ID: ref, FS: builtinFS, Root: "template/" + ref,
+		Source: templateSource{Kind: sourceKindBuiltin, Location: ref},
+	}, nil
+}
+
+// isDirTemplateRef reports whether ref is a path rather than the name of a
+// built-in template.
+func isDirTemplateRef(ref string) bool {
+	return strings.HasPrefix(ref, ".") || strings.HasPrefix(ref, "~/") || filepath.IsAbs(ref) || strings.ContainsAny(ref, `/\`)
+}
+
+// parseGitTemplateRef splits a git template reference:
+//
+//	git+https://github.com/company/templates.git//service#v1.2.0
+//	git@github.com:company/templates.git#main
+//
+// into the repository URL, the revision and the template directory in it.
+func parseGitTemplateRef(ref string) (url string, rev string, subdir string, ok bool) {
+	if !strings.HasPrefix(ref, "git+") && !strings.HasPrefix(ref, "git@") && !strings.Contains(ref, "://") {
+		return "", "", "", false
+	}
+	url = strings.TrimPrefix(ref, "git+")
+	if i := strings.LastIndex(url, "#"); i >= 0 {
+		url, rev = url[:i], url[i+1:]
+	}
+	start := 0
+	if i := strings.Index(url, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(url[start:], "//"); i >= 0 {
+		url, subdir = url[:start+i], strings.Trim(url[start+i+2:], "/")
+	}
+	return url, rev, subdir, true
+}
+
+// gitTemplateCacheDir returns the directory of the checkout of rev of the
+// repository in the user cache.
+func gitTemplateCacheDir(url string, rev string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(url + "#" + rev))
+	return filepath.Join(cacheDir, "beginning", "templates", hex.EncodeToString(sum[:8])), nil
+}
+
+// fetchGitTemplate checks out rev (the default branch when empty) of the
+// repository in the user cache. With refresh, as for git references given
+// to --type, it is fetched again on every use so branches stay current;
+// sources of the user configuration are fetched once, then on source
+// refresh. A cached checkout is used when the fetch fails, e.g. offline.
+func fetchGitTemplate(url string, rev string, refresh bool) (string, error) {
+	dir, err := gitTemplateCacheDir(url, rev)
+	if err != nil {
+		return "", err
+	}
+
+	cached := isDir(filepath.Join(dir, ".git"))
+	if cached && !refresh {
+		return dir, nil
+	}
+	if !cached {
+		if err := gitCommand("", "init", "--quiet", dir); err != nil {
+			return "", err
+		}
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	fmt.Printf("⚙️  Fetching template %s#%s\n", url, rev)
+	err = gitCommand(dir, "fetch", "--quiet", "--depth", "1", url, rev)
+	if err == nil {
+		err = gitCommand(dir, "checkout", "--quiet", "--force", "FETCH_HEAD")
+	}
+	if err != nil {
+		if !cached {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("fetch template %s#%s: %w", url, rev, err)
+		}
+		fmt.Printf("⚠️  Fetching %s#%s failed (%v), using the cached copy\n", url, rev, err)
+	}
+	return dir, nil
+}
+
+// builtinTemplates lists the templates embedded in the binary.
+func builtinTemplates() []string {
+	entries, err := fs.ReadDir(builtinFS, "template")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), "_") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// chain returns the template and its ancestors, the root ancestor first.
+func (t *projectTemplate) chain() []*projectTemplate {
+	var chain []*projectTemplate
+	for current := t; current != nil; current = current.Parent {
+		chain = append([]*projectTemplate{current}, chain...)
+	}
+	return chain
+}
+
+// components lists the optional components of the template and its
+// ancestors. A component of a child extends the one of its parent.
+func (t *projectTemplate) components() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, level := range t.chain() {
+		entries, err := fs.ReadDir(level.FS, path.Join(level.Root, "_components"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// componentDescription returns the description of a component, from the
+// closest template of the chain describing it.
+func (t *projectTemplate) componentDescription(component string) string {
+	for current := t; current != nil; current = current.Parent {
+		if description, ok := current.Manifest.Components[component]; ok {
+			return description
+		}
+	}
+	return ""
+}
+
+func (t *projectTemplate) hasComponent(component string) bool {
+	for _, name := range t.components() {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns the name of the template, its directory by default.
+func (t *projectTemplate) name() string {
+	if t.Manifest.Name != "" {
+		return t.Manifest.Name
+	}
+	if t.Dir != "" {
+		return filepath.Base(t.Dir)
+	}
+	return path.Base(t.Root)
+}
+
+// summary returns the first line of the description.
+func (t *projectTemplate) summary() string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(t.Manifest.Description), "\n")
+	return summary
+}
+
+// goVersion returns the minimum Go version of the chain, the highest one
+// declared, or the minimum of beginning.
+func (t *projectTemplate) goVersion() string {
+	version := minGoVersion
+	for _, level := range t.chain() {
+		if level.Manifest.GoVersion != "" && compareGoVersions(level.Manifest.GoVersion, version) > 0 {
+			version = level.Manifest.GoVersion
+		}
+	}
+	return version
+}
+
+// hasTags reports whether the template has every tag of tags.
+func (t *projectTemplate) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, own := range t.Manifest.Tags {
+			found = found || strings.EqualFold(own, tag)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasFile reports whether the template or an ancestor has the file at the
+// root of its tree.
+func (t *projectTemplate) hasFile(name string) bool {
+	for current := t; current != nil; current = current.Parent {
+		if _, err := fs.Stat(current.FS, path.Join(current.Root, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// variables merges the variables of the chain, a child redefining a variable
+// of its parent overrides it.
+func (t *projectTemplate) variables() []templateVariable {
+	var variables []templateVariable
+	index := map[string]int{}
+	for _, level := range t.chain() {
+		for _, variable := range level.Manifest.Variables {
+			if i, ok := index[variable.Name]; ok {
+				variables[i] = variable
+				continue
+			}
+			index[variable.Name] = len(variables)
+			variables = append(variables, variable)
+		}
+	}
+	return variables
+}
+
+// postHooks returns the post hooks of the chain, the ones of the parents
+// first.
+func (t *projectTemplate) postHooks() []string {
+	var hooks []string
+	for _, level := range t.chain() {
+		hooks = append(hooks, level.Manifest.Hooks.Post...)
+	}
+	return hooks
+}
+
+// applyVariables sets the variables of the template in values from the
+// defaults, and reports unknown and missing ones.
+func (t *projectTemplate) applyVariables(values *Values) error {
+	variables := t.variables()
+	known := map[string]bool{}
+	var errs []string
+	if values.Vars == nil {
+		values.Vars = map[string]string{}
+	}
+	for _, variable := range variables {
+		known[variable.Name] = true
+		if _, ok := values.Vars[variable.Name]; ok {
+			continue
+		}
+		if variable.Required {
+			errs = append(errs, fmt.Sprintf("variable %s is required (%s), set it with --set %s=<value>", variable.Name, variable.Description, variable.Name))
+			continue
+		}
+		values.Vars[variable.Name] = variable.Default
+	}
+	for name := range values.Vars {
+		if !known[name] {
+			errs = append(errs, fmt.Sprintf("unknown variable %s, template %s declares: %s", name, t.Name, strings.Join(variableNames(variables), ", ")))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func variableNames(variables []templateVariable) []string {
+	names := []string{}
+	for _, variable := range variables {
+		names = append(names, variable.Name)
+	}
+	if len(names) == 0 {
+		names = append(names, "none")
+	}
+	return names
+}
+
+// render writes the project into outputDir: the tree of the parent, then
+// the deletes of the manifest, the included partials and the files of the
+// template, each level with its part of the enabled components. It returns
+// the delete patterns that matched no file.
+func (t *projectTemplate) render(outputDir string, values Values) ([]string, error) {
+	partials, err := t.partials()
+	if err != nil {
+		return nil, err
+	}
+	return t.renderWith(outputDir, values, partials)
+}
+
+func (t *projectTemplate) renderWith(outputDir string, values Values, partials *partials) ([]string, error) {
+	var unmatched []string
+	if t.Parent != nil {
+		parentUnmatched, err := t.Parent.renderWith(outputDir, values, partials)
+		if err != nil {
+			return nil, err
+		}
+		unmatched = parentUnmatched
+		deleteUnmatched, err := t.deleteFiles(outputDir, values)
+		if err != nil {
+			return nil, err
+		}
+		unmatched = append(unmatched, deleteUnmatched...)
+	}
+
+	for _, name := range t.Manifest.Include {
+		if err := partials.include(name, outputDir, values); err != nil {
+			return nil, fmt.Errorf("include %s of %s: %w", name, t.ID, err)
+		}
+	}
+	if err := renderTree(t.FS, t.Root, outputDir, values, partials.set); err != nil {
+		return nil, err
+	}
+	for _, component := range values.Components {
+		componentPath := path.Join(t.Root, "_components", component)
+		if _, err := fs.Stat(t.FS, componentPath); err != nil {
+			continue
+		}
+		if err := renderTree(t.FS, componentPath, outputDir, values, partials.set); err != nil {
+			return nil, err
+		}
+	}
+	return unmatched, nil
+}
+
+// deleteFiles removes the files and directories matching the delete
+// patterns of the manifest.
+func (t *projectTemplate) deleteFiles(outputDir string, values Values) ([]string, error) {
+	var unmatched []string
+	for _, pattern := range t.Manifest.Delete {
+		rendered, err := templatePathFunc(pattern, values)
+		if err != nil {
+			return nil, fmt.Errorf("delete %q of %s: %w", pattern, t.ID, err)
+		}
+		matches, err := filepath.Glob(filepath.Join(outputDir, filepath.FromSlash(rendered)))
+		if err != nil {
+			return nil, fmt.Errorf("delete %q of %s: %w", pattern, t.ID, err)
+		}
+		if len(matches) == 0 {
+			unmatched = append(unmatched, fmt.Sprintf("%s (%s)", pattern, t.ID))
+		}
+		for _, match := range matches {
+			if err := os.RemoveAll(match); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return unmatched, nil
+}
+
+// runPostHooks runs the post hooks of the chain in the project directory.
+func (t *projectTemplate) runPostHooks(values Values) error {
+	for _, hook := range t.postHooks() {
+		command, err := templatePathFunc(hook, values)
+		if err != nil {
+			return fmt.Errorf("hook %q: %w", hook, err)
+		}
+		if err := runCommand(command); err != nil {
+			return fmt.Errorf("hook %q: %w", command, err)
+		}
+	}
+	return nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// useBuiltinTemplates makes files the built-in templates for the test, with
+// an empty user configuration.
+func useBuiltinTemplates(t *testing.T, files fstest.MapFS) {
+	t.Helper()
+	saved := builtinFS
+	builtinFS = files
+	t.Cleanup(func() { builtinFS = saved })
+	t.Setenv(userConfigEnv, filepath.Join(t.TempDir(), "config.yaml"))
+}
+
+func mapFile(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestResolveTemplateErrors(t *testing.T) {
+	tests := map[string]struct {
+		files fstest.MapFS
+		want  string
+	}{
+		"cycle": {
+			files: fstest.MapFS{
+				"template/a/_template.yaml": mapFile("extends: b\n"),
+				"template/b/_template.yaml": mapFile("extends: c\n"),
+				"template/c/_template.yaml": mapFile("extends: a\n"),
+			},
+			want: "template inheritance cycle: a → b → c → a",
+		},
+		"self": {
+			files: fstest.MapFS{
+				"template/a/_template.yaml": mapFile("extends: a\n"),
+			},
+			want: "template inheritance cycle: a → a",
+		},
+		"unknown parent": {
+			files: fstest.MapFS{
+				"template/a/_template.yaml": mapFile("extends: b\n"),
+				"template/b/_template.yaml": mapFile("extends: missing\n"),
+			},
+			want: `template a extends b: template b extends missing: unknown template "missing"`,
+		},
+		"unknown manifest field": {
+			files: fstest.MapFS{
+				"template/a/_template.yaml": mapFile("extend: b\n"),
+			},
+			want: "_template.yaml of a: yaml: unmarshal errors",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			useBuiltinTemplates(t, test.files)
+			_, err := resolveTemplate("a")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("resolveTemplate(a) = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+// chainTemplates is a child extending a parent, both with a component c.
+var chainTemplates = fstest.MapFS{
+	"template/parent/_template.yaml":              mapFile("hooks:\n  post:\n    - echo parent >> hooks.log\n"),
+	"template/parent/both.txt":                    mapFile("parent"),
+	"template/parent/parent.txt.tmpl":             mapFile("parent of {{.RepoName}}"),
+	"template/parent/gone/a.txt":                  mapFile("deleted by the child"),
+	"template/parent/_components/c/both.txt":      mapFile("parent c"),
+	"template/parent/_components/c/component.txt": mapFile("parent c"),
+	"template/parent/_components/c/shared.txt":    mapFile("parent c"),
+	"template/child/_template.yaml": mapFile(`extends: parent
+delete:
+  - gone
+  - "{{.RepoName}}.txt"
+hooks:
+  post:
+    - echo {{.RepoName}} >> hooks.log
+    - exit 3
+    - echo after >> hooks.log
+`),
+	"template/child/both.txt":               mapFile("child"),
+	"template/child/shared.txt":             mapFile("child"),
+	"template/child/_components/c/both.txt": mapFile("child c"),
+}
+
+func TestRenderTemplateChain(t *testing.T) {
+	useBuiltinTemplates(t, chainTemplates)
+	child, err := resolveTemplate("child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+	unmatched, err := child.render(outputDir, Values{RepoName: "demo", Components: []string{"c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each level renders its files then its components over its parent
+	expected := map[string]string{
+		"both.txt":      "child c",
+		"parent.txt":    "parent of demo",
+		"component.txt": "parent c",
+		"shared.txt":    "child",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "gone")); !os.IsNotExist(err) {
+		t.Errorf("gone was not deleted: %v", err)
+	}
+	if want := []string{"{{.RepoName}}.txt (child)"}; !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched delete patterns = %q, want %q", unmatched, want)
+	}
+}
+
+func TestRunPostHooks(t *testing.T) {
+	useBuiltinTemplates(t, chainTemplates)
+	child, err := resolveTemplate("child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err = inDir(dir, func() error {
+		return child.runPostHooks(Values{RepoName: "demo"})
+	})
+	if err == nil || !strings.Contains(err.Error(), `hook "exit 3"`) {
+		t.Fatalf("runPostHooks = %v, want the error of exit 3", err)
+	}
+	// The parent hooks run first, none after the failing one
+	log, err := os.ReadFile(filepath.Join(dir, "hooks.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "parent\ndemo\n"; string(log) != want {
+		t.Errorf("hooks.log = %q, want %q", log, want)
+	}
+}
@@ -0,0 +1,7 @@
+package httpd
+
+func NewServer(handler http.Handler, verifier *auth.Verifier) *http.Server {
+	return &http.Server{
+		Handler: middleware.Auth(verifier, handler),
+	}
+}
@@ -0,0 +1,7 @@
+package httpd
+
+func NewServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler: middleware.Recover(handler),
+	}
+}
@@ -0,0 +1,10 @@
+package httpd
+
+// NewServer returns the HTTP server of the application.
+func NewServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:        ":3000",
+		Handler:     handler,
+		ReadTimeout: 5 * time.Second,
+	}
+}
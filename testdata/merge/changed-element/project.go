@@ -0,0 +1,11 @@
+package httpd
+
+// NewServer returns the HTTP server of the application.
+func NewServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:        ":8080",
+		Handler:     handler,
+		ReadTimeout: 10 * time.Second,
+		IdleTimeout: time.Minute,
+	}
+}
@@ -0,0 +1,12 @@
+package httpd
+
+// NewServer returns the HTTP server of the application, its handler
+// protected by the auth middleware.
+func NewServer(handler http.Handler, verifier *auth.Verifier) *http.Server {
+	return &http.Server{
+		Addr:        ":8080",
+		Handler:     middleware.Auth(verifier, handler),
+		ReadTimeout: 10 * time.Second,
+		IdleTimeout: time.Minute,
+	}
+}
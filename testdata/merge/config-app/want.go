@@ -0,0 +1,22 @@
+package config
+
+type App struct {
+	Server   Server   `json:"server" yaml:"server"`
+	Database Database `json:"database" yaml:"database"`
+	OTEL     OTEL     `json:"otel" yaml:"otel"`
+	Logging  Logging  `json:"logging" yaml:"logging"`
+	Auth     Auth     `json:"auth" yaml:"auth"`
+	Orders   Orders   `json:"orders" yaml:"orders"`
+}
+
+// Validate reports every problem of the configuration, one per line.
+func (app *App) Validate() error {
+	v := &validator{}
+	app.Server.validate(v)
+	app.Database.validate(v)
+	app.OTEL.validate(v)
+	app.Logging.validate(v)
+	app.Auth.validate(v)
+	app.Orders.validate(v)
+	return v.err()
+}
@@ -0,0 +1,16 @@
+package config
+
+// App is the configuration of the application.
+type App struct {
+	Server Server // the HTTP server
+	Auth   Auth   // the verification of tokens
+}
+
+func (app *App) Validate() error {
+	return nil
+}
+
+// Secrets lists the values to redact from the logs.
+func (app *App) Secrets() []string {
+	return []string{app.Auth.Secret}
+}
@@ -0,0 +1,10 @@
+package config
+
+// App is the configuration of the application.
+type App struct {
+	Server Server // the HTTP server
+}
+
+func (app *App) Validate() error {
+	return nil
+}
@@ -0,0 +1,18 @@
+package config
+
+// App is the configuration of the application, loaded from
+// config/config.yaml and the environment.
+type App struct {
+	// Server is the HTTP server.
+	Server Server // the HTTP server
+
+	// Orders are the limits of the order service.
+	Orders Orders
+}
+
+func (app *App) Validate() error {
+	// Every section validates itself
+	return nil
+}
+
+func (app *App) Print() {}
@@ -0,0 +1,24 @@
+package config
+
+// App is the configuration of the application, loaded from
+// config/config.yaml and the environment.
+type App struct {
+	// Server is the HTTP server.
+	Server Server // the HTTP server
+	Auth   Auth   // the verification of tokens
+
+	// Orders are the limits of the order service.
+	Orders Orders
+}
+
+func (app *App) Validate() error {
+	// Every section validates itself
+	return nil
+}
+
+// Secrets lists the values to redact from the logs.
+func (app *App) Secrets() []string {
+	return []string{app.Auth.Secret}
+}
+
+func (app *App) Print() {}
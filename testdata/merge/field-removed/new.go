@@ -0,0 +1,5 @@
+package config
+
+type App struct {
+	Server Server
+}
@@ -0,0 +1,6 @@
+package config
+
+type App struct {
+	Server Server
+	Legacy Legacy
+}
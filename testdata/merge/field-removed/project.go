@@ -0,0 +1,7 @@
+package config
+
+type App struct {
+	Server Server
+	Legacy Legacy
+	Orders Orders
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import (
+	"context"
+
+	"example.com/demo/internal/auth"
+	"example.com/demo/internal/config"
+)
+
+func run(ctx context.Context, app *config.App) { auth.Check(ctx) }
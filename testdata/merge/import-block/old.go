@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"context"
+
+	"example.com/demo/internal/config"
+)
+
+func run(ctx context.Context, app *config.App) {}
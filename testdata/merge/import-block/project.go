@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+
+	cfg "example.com/demo/internal/config"
+	"example.com/demo/internal/orders"
+)
+
+func run(ctx context.Context, app *cfg.App) {}
+
+func init() {
+	slog.Info("orders", "enabled", orders.Enabled)
+}
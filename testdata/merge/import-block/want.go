@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+
+	"example.com/demo/internal/auth"
+	cfg "example.com/demo/internal/config"
+	"example.com/demo/internal/orders"
+)
+
+func run(ctx context.Context, app *cfg.App) { auth.Check(ctx) }
+
+func init() {
+	slog.Info("orders", "enabled", orders.Enabled)
+}
@@ -0,0 +1,11 @@
+package cmd
+
+import (
+	"example.com/demo/internal/auth"
+	"example.com/demo/internal/config"
+)
+
+func load() *config.App {
+	auth.Register()
+	return &config.App{}
+}
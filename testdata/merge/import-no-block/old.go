@@ -0,0 +1,7 @@
+package cmd
+
+import "example.com/demo/internal/config"
+
+func load() *config.App {
+	return &config.App{}
+}
@@ -0,0 +1,8 @@
+package cmd
+
+import "example.com/demo/internal/config"
+
+// load reads the configuration.
+func load() *config.App {
+	return &config.App{}
+}
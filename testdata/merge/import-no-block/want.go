@@ -0,0 +1,13 @@
+package cmd
+
+import "example.com/demo/internal/config"
+
+import (
+	"example.com/demo/internal/auth"
+)
+
+// load reads the configuration.
+func load() *config.App {
+	auth.Register()
+	return &config.App{}
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import (
+	"example.com/demo/internal/config"
+	"example.com/demo/internal/legacy"
+)
+
+var _ = legacy.Value
+
+func load() *config.App { return nil }
@@ -0,0 +1,9 @@
+package cmd
+
+func initHTTPDApplication() {
+	wire.Build(router.ProviderSetRouter, adapter.NewLogger, auth.ProviderSetAuth)
+}
+
+func initMigrator() {
+	wire.Build(adapter.NewCache, adapter.NewDB, migration.NewMigrator)
+}
@@ -0,0 +1,9 @@
+package cmd
+
+func initHTTPDApplication() {
+	wire.Build(router.ProviderSetRouter, adapter.NewLogger)
+}
+
+func initMigrator() {
+	wire.Build(adapter.NewDB, migration.NewMigrator)
+}
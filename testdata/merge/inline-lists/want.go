@@ -0,0 +1,9 @@
+package cmd
+
+func initHTTPDApplication() {
+	wire.Build(router.ProviderSetRouter, adapter.NewLogger, auth.ProviderSetAuth, queue.NewPublisher)
+}
+
+func initMigrator() {
+	wire.Build(adapter.NewCache, adapter.NewDB, migration.NewMigrator, migration.NewReporter)
+}
@@ -1,8 +1,7 @@
 package router
 
-import "github.com/google/wire"
-
 var ProviderSetRouter = wire.NewSet(
-	NewHealthRouter,
 	NewReadyRouter,
+	NewHealthRouter,
+	NewAdminRouter,
 )
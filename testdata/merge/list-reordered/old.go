@@ -0,0 +1,7 @@
+package router
+
+var ProviderSetRouter = wire.NewSet(
+	NewHealthRouter,
+	NewReadyRouter,
+	NewAdminRouter,
+)
@@ -0,0 +1,8 @@
+package router
+
+var ProviderSetRouter = wire.NewSet(
+	NewHealthRouter,
+	NewReadyRouter,
+	NewAdminRouter,
+	NewOrderRouter,
+)
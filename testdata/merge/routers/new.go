@@ -0,0 +1,25 @@
+package router
+
+import "github.com/google/wire"
+
+// ProviderSetRouter provides the routers, collected in Routers. A router is
+// added with its constructor here and its field in Routers; the routers
+// generated from an OpenAPI spec come in through ProviderSetAPI.
+var ProviderSetRouter = wire.NewSet(
+	NewHealthRouter,
+	NewReadyRouter,
+	NewAdminRouter,
+	NewMeRouter,
+	wire.Struct(new(Routers), "*"),
+	NewRouters,
+	ProviderSetAPI,
+)
+
+// Routers are the routers served by the HTTP server, filled in by wire.
+type Routers struct {
+	Health *HealthRouter
+	Ready  *ReadyRouter
+	Me     *MeRouter
+	Admin  *AdminRouter
+	API    APIRouters
+}
@@ -0,0 +1,69 @@
+//go:build wireinject
+// +build wireinject
+
+package cmd
+
+import (
+	"example.com/demo/internal/adapter"
+	"example.com/demo/internal/adapter/repository"
+	"example.com/demo/internal/auth"
+	"github.com/google/wire"
+	"github.com/zeroxsolutions/barbatos/app"
+	"example.com/demo/internal/config"
+	"example.com/demo/internal/entrypoint"
+	"example.com/demo/internal/entrypoint/httpd"
+	"example.com/demo/internal/entrypoint/httpd/controller"
+	"example.com/demo/internal/entrypoint/httpd/router"
+	"example.com/demo/internal/middleware"
+	"example.com/demo/internal/migration"
+	"example.com/demo/internal/service"
+)
+
+// providerSetLayers composes the repository, service and middleware layers,
+// so providers added to their sets are injected without editing this file.
+var providerSetLayers = wire.NewSet(
+	repository.ProviderSetRepository,
+	service.ProviderSetService,
+	middleware.ProviderSetMiddleware,
+)
+
+func initHTTPDApplication(
+	appConfig *config.App,
+) (app.App, error) {
+	wire.Build(
+		controller.ProviderSetController,
+		router.ProviderSetRouter,
+		httpd.ProviderSetHTTPServer,
+		auth.ProviderSetAuth,
+		providerSetLayers,
+		adapter.NewLogLevel,
+		adapter.NewLogger,
+		adapter.NewOTEL,
+		adapter.NewDB,
+		adapter.NewDatabaseChecker,
+		adapter.NewReadinessCheckers,
+		entrypoint.ProviderSetEntrypoint,
+	)
+	return nil, nil
+}
+
+
+func initMigrator(
+	appConfig *config.App,
+) (*migration.Migrator, error) {
+	wire.Build(
+		adapter.NewDB,
+		migration.NewMigrator,
+	)
+	return nil, nil
+}
+
+func initSeeder(
+	appConfig *config.App,
+) (*migration.Seeder, error) {
+	wire.Build(
+		adapter.NewDB,
+		migration.NewSeeder,
+	)
+	return nil, nil
+}
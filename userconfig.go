@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// userConfigEnv overrides the path of the user configuration.
+const userConfigEnv = "BEGINNING_CONFIG"
+
+// userConfig holds the defaults of the user and the template sources, in
+// $XDG_CONFIG_HOME/beginning/config.yaml:
+//
+//	modulePrefix: github.com/ourorg
+//	author: Jane Doe <jane@ourorg.com>
+//	license: Apache-2.0
+//	sources:
+//	  ourorg:
+//	    kind: git
+//	    location: https://github.com/ourorg/templates.git#main
+//	aliases:
+//	  api: ourorg/grpc-service
+type userConfig struct {
+	ModulePrefix  string `yaml:"modulePrefix,omitempty"`
+	Author        string `yaml:"author,omitempty"`
+	License       string `yaml:"license,omitempty"`
+	LicenseHolder string `yaml:"licenseHolder,omitempty"`
+	GoVersion     string `yaml:"goVersion,omitempty"`
+	OutputRoot    string `yaml:"outputRoot,omitempty"`
+	Type          string `yaml:"type,omitempty"`
+	GitBranch     string `yaml:"gitBranch,omitempty"`
+	// Sources are the template sources by name: create -t ourorg/api uses
+	// the api template of the source ourorg.
+	Sources map[string]templateSource `yaml:"sources,omitempty"`
+	// Aliases name templates, e.g. api for ourorg/grpc-service.
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+}
+
+// userConfigKey is a default of the user configuration, as named by
+// beginning config get and set.
+type userConfigKey struct {
+	Name        string
+	Description string
+	Field       func(c *userConfig) *string
+	// Normalize validates a value and returns it as it is stored.
+	Normalize func(value string) (string, error)
+}
+
+var userConfigKeys = []userConfigKey{
+	{
+		Name:        "module-prefix",
+		Description: "Prefix of the module of new projects, e.g. github.com/ourorg",
+		Field:       func(c *userConfig) *string { return &c.ModulePrefix },
+		Normalize: func(value string) (string, error) {
+			return strings.TrimSuffix(value, "/"), nil
+		},
+	},
+	{
+		Name:        "author",
+		Description: "Author of initial commits and default license holder, \"Name <email>\"",
+		Field:       func(c *userConfig) *string { return &c.Author },
+		Normalize: func(value string) (string, error) {
+			address, err := mail.ParseAddress(value)
+			if err != nil {
+				return "", fmt.Errorf("expected \"Name <email>\": %w", err)
+			}
+			return address.String(), nil
+		},
+	},
+	{
+		Name:        "license",
+		Description: "SPDX identifier of the license of new projects",
+		Field:       func(c *userConfig) *string { return &c.License },
+		Normalize: func(value string) (string, error) {
+			p, err := loadPartials(nil)
+			if err != nil {
+				return "", err
+			}
+			return p.licenseID(value)
+		},
+	},
+	{
+		Name:        "license-holder",
+		Description: "Copyright holder of the license, the name of the author by default",
+		Field:       func(c *userConfig) *string { return &c.LicenseHolder },
+	},
+	{
+		Name:        "go-version",
+		Description: "Go version of new projects",
+		Field:       func(c *userConfig) *string { return &c.GoVersion },
+		Normalize: func(value string) (string, error) {
+			if !isValidGoVersion(value) {
+				return "", fmt.Errorf("expected a Go version of %s or later", minGoVersion)
+			}
+			return value, nil
+		},
+	},
+	{
+		Name:        "output-root",
+		Description: "Directory new projects are created in, the current one by default",
+		Field:       func(c *userConfig) *string { return &c.OutputRoot },
+	},
+	{
+		Name:        "type",
+		Description: "Template of create without --type",
+		Field:       func(c *userConfig) *string { return &c.Type },
+	},
+	{
+		Name:        "git-branch",
+		Description: "Default branch of repositories created with --git",
+		Field:       func(c *userConfig) *string { return &c.GitBranch },
+	},
+}
+
+// userConfigPath returns the path of the user configuration, which may not
+// exist.
+func userConfigPath() (string, error) {
+	if path := os.Getenv(userConfigEnv); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "beginning", "config.yaml"), nil
+}
+
+// loadUserConfig reads the user configuration, empty when there is none.
+func loadUserConfig() (*userConfig, error) {
+	cfg := &userConfig{}
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// save writes the user configuration.
+func (c *userConfig) save() error {
+	path, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// authorName returns the name of the author, without the email.
+func (c *userConfig) authorName() string {
+	if address, err := mail.ParseAddress(c.Author); err == nil {
+		return address.Name
+	}
+	return ""
+}
+
+// templateType returns the template of create, ref when it is given.
+func (c *userConfig) templateType(ref string) string {
+	return firstNonEmpty(ref, c.Type, "service")
+}
+
+// outputDir returns the directory of a new project named repoName.
+func (c *userConfig) outputDir(repoName string) (string, error) {
+	if c.OutputRoot == "" {
+		return "./" + repoName, nil
+	}
+	root, err := expandHome(c.OutputRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, repoName), nil
+}
+
+// expandHome replaces a leading ~/ of path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func findUserConfigKey(name string) (userConfigKey, error) {
+	var names []string
+	for _, key := range userConfigKeys {
+		if key.Name == name {
+			return key, nil
+		}
+		names = append(names, key.Name)
+	}
+	return userConfigKey{}, fmt.Errorf("unknown key %q, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// mustLoadUserConfig loads the user configuration or exits.
+func mustLoadUserConfig() *userConfig {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Printf("❌ Error reading the user configuration: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	key, err := findUserConfigKey(args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), *key.Field(mustLoadUserConfig()))
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key, err := findUserConfigKey(args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	value := args[1]
+	if value != "" && key.Normalize != nil {
+		if value, err = key.Normalize(value); err != nil {
+			fmt.Printf("❌ Invalid %s: %v\n", key.Name, err)
+			os.Exit(1)
+		}
+	}
+	cfg := mustLoadUserConfig()
+	*key.Field(cfg) = value
+	check(cfg.save())
+	if value == "" {
+		fmt.Printf("✅ Unset %s\n", key.Name)
+		return
+	}
+	fmt.Printf("✅ Set %s to %s\n", key.Name, value)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	cfg := mustLoadUserConfig()
+	path, err := userConfigPath()
+	check(err)
+	fmt.Printf("# %s\n", path)
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, key := range userConfigKeys {
+		fmt.Fprintf(w, "%s\t%s\t# %s\n", key.Name, orDash(*key.Field(cfg)), key.Description)
+	}
+	check(w.Flush())
+}
+
+func completeUserConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []string
+	for _, key := range userConfigKeys {
+		keys = append(keys, key.Name+"\t"+key.Description)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
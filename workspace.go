@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workspace is a go.work found above the directory of a new project.
+type workspace struct {
+	Dir string
+}
+
+// findWorkspace returns the workspace dir belongs to, nil outside of one.
+func findWorkspace(dir string) *workspace {
+	for {
+		if fileExists(filepath.Join(dir, "go.work")) {
+			return &workspace{Dir: dir}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// useDirs returns the module directories of the use directives of go.work.
+func (w *workspace) useDirs() ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(w.Dir, "go.work"))
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, strings.Trim(line, `"`))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(line[len("use "):]), `"`))
+		}
+	}
+	return dirs, scanner.Err()
+}
+
+// modulePath derives the module path of a project created in dir from the
+// modules of the workspace: a module at <workspace>/pkg with the path
+// example.com/backend/pkg puts dir <workspace>/services/api at
+// example.com/backend/services/api.
+func (w *workspace) modulePath(dir string) (string, error) {
+	relDir, err := filepath.Rel(w.Dir, dir)
+	if err != nil {
+		return "", err
+	}
+	dirs, err := w.useDirs()
+	if err != nil {
+		return "", err
+	}
+	for _, useDir := range dirs {
+		goMod, err := os.ReadFile(filepath.Join(w.Dir, useDir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		match := reGoModule.FindSubmatch(goMod)
+		if match == nil {
+			continue
+		}
+		path := string(match[1])
+		rel := filepath.ToSlash(filepath.Clean(useDir))
+		if rel == "." {
+			return path + "/" + filepath.ToSlash(relDir), nil
+		}
+		if strings.HasSuffix(path, "/"+rel) {
+			return strings.TrimSuffix(path, rel) + filepath.ToSlash(relDir), nil
+		}
+	}
+	return "", fmt.Errorf("no module of %s has a path ending in its directory", filepath.Join(w.Dir, "go.work"))
+}
+
+// dedupe removes what the workspace already provides from a project created
+// in it: the .gitignore patterns of the workspace and the bin/ scripts
+// identical to those of the workspace, like bin/test.sh which already tests
+// every module. bin/utils.sh stays while other scripts of the project source
+// it.
+func (w *workspace) dedupe(outputDir string) error {
+	if err := w.dedupeGitignore(outputDir); err != nil {
+		return err
+	}
+
+	binDir := filepath.Join(outputDir, "bin")
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return nil
+	}
+	var kept []string
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "utils.sh" {
+			continue
+		}
+		if sameFile(filepath.Join(binDir, entry.Name()), filepath.Join(w.Dir, "bin", entry.Name())) {
+			fmt.Printf("Skipping bin/%s, the workspace provides it\n", entry.Name())
+			if err := os.Remove(filepath.Join(binDir, entry.Name())); err != nil {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, entry.Name())
+	}
+	utils := filepath.Join(binDir, "utils.sh")
+	if fileExists(utils) && !sourcesUtils(binDir, kept) {
+		if err := os.Remove(utils); err != nil {
+			return err
+		}
+	}
+	if entries, err := os.ReadDir(binDir); err == nil && len(entries) == 0 {
+		return os.Remove(binDir)
+	}
+	return nil
+}
+
+// dedupeGitignore drops the patterns of the project .gitignore already in the
+// workspace one, and the go.work files which belong to the workspace root.
+// Blocks left without patterns are dropped with their comments, and the file
+// when nothing is left.
+func (w *workspace) dedupeGitignore(outputDir string) error {
+	path := filepath.Join(outputDir, ".gitignore")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	known := map[string]bool{"go.work": true, "go.work.sum": true}
+	if rootContent, err := os.ReadFile(filepath.Join(w.Dir, ".gitignore")); err == nil {
+		for _, line := range strings.Split(string(rootContent), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				known[line] = true
+			}
+		}
+	}
+
+	var blocks []string
+	for _, block := range strings.Split(string(content), "\n\n") {
+		var lines []string
+		patterns := 0
+		for _, line := range strings.Split(block, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				lines = append(lines, line)
+				continue
+			}
+			if !known[trimmed] {
+				lines = append(lines, line)
+				patterns++
+			}
+		}
+		if patterns > 0 {
+			blocks = append(blocks, strings.Trim(strings.Join(lines, "\n"), "\n"))
+		}
+	}
+	if len(blocks) == 0 {
+		fmt.Println("Skipping .gitignore, the workspace provides it")
+		return os.Remove(path)
+	}
+	return os.WriteFile(path, []byte(strings.Join(blocks, "\n\n")+"\n"), 0644)
+}
+
+func sameFile(a string, b string) bool {
+	contentA, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	contentB, err := os.ReadFile(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(contentA, contentB)
+}
+
+func sourcesUtils(binDir string, names []string) bool {
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(binDir, name))
+		if err == nil && bytes.Contains(content, []byte("utils.sh")) {
+			return true
+		}
+	}
+	return false
+}